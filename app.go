@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"webook/config"
+	"webook/internal/service/sms/async"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// App 管理 webook 的生命周期：启动 HTTP 服务与后台任务，收到退出信号之后
+// 先等待正在处理的请求与后台任务完成，再依次关闭数据库与 Redis 连接池
+type App struct {
+	cfg      *config.Config
+	server   *http.Server
+	asyncSMS *async.Service
+	db       *gorm.DB
+	redis    redis.Cmdable
+}
+
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	a.asyncSMS.Start()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Println("HTTP 服务启动......", a.server.Addr)
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+		// 启动失败，例如端口被占用，仍然需要释放资源
+		err = errors.Join(err, a.shutdown())
+	case <-ctx.Done():
+		log.Println("收到退出信号，开始优雅退出......")
+		err = a.shutdown()
+	}
+
+	return errors.Join(err, a.close())
+}
+
+func (a *App) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	// 不再接收新请求，等待正在处理的请求完成
+	err := a.server.Shutdown(ctx)
+	// 没有新的短信进来之后再停止后台发送
+	return errors.Join(err, a.asyncSMS.Stop(ctx))
+}
+
+// 按照依赖顺序关闭连接池，HTTP 请求全部结束之后才能关闭
+func (a *App) close() error {
+	var errs []error
+	if sqlDB, err := a.db.DB(); err == nil {
+		errs = append(errs, sqlDB.Close())
+	} else {
+		errs = append(errs, err)
+	}
+
+	if closer, ok := a.redis.(io.Closer); ok {
+		errs = append(errs, closer.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,392 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// 环境变量前缀，例如 WEBOOK_DB_DNS 覆盖 db.dns
+const envPrefix = "WEBOOK"
+
+// Load 按照 默认值 < 配置文件 < 环境变量 < 命令行参数 的优先级加载配置
+func Load(args []string) (*Config, error) {
+	m, err := NewManager(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Config(), nil
+}
+
+// Manager 持有当前生效的配置，配置文件变更之后重新加载并通知订阅者
+type Manager struct {
+	v   *viper.Viper
+	cur atomic.Pointer[Config]
+
+	// 保证订阅者按照配置变更的顺序收到通知
+	mu          sync.Mutex
+	subscribers []func(old, cur *Config)
+}
+
+func NewManager(args []string) (*Manager, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	fs := pflag.NewFlagSet("webook", pflag.ContinueOnError)
+	file := fs.String("config", "config/dev.yaml", "配置文件路径，支持 yaml、toml")
+	fs.String("http.addr", "", "HTTP 监听地址")
+	fs.String("db.dns", "", "MySQL DSN")
+	fs.String("redis.addr", "", "Redis 地址")
+	fs.String("sms.provider", "", "短信服务商：memory、tencent、aliyun")
+	fs.Int("ratelimit.rate", 0, "限流阈值")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// 只绑定显式传入的参数，没传的参数不能用空值覆盖配置文件
+	fs.Visit(func(f *pflag.Flag) {
+		if f.Name != "config" {
+			_ = v.BindPFlag(f.Name, f)
+		}
+	})
+
+	v.SetConfigFile(*file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %w", *file, err)
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	m := &Manager{v: v}
+	cfg, err := m.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	m.cur.Store(cfg)
+	return m, nil
+}
+
+// 当前生效的配置，调用方不能修改返回值
+func (m *Manager) Config() *Config {
+	return m.cur.Load()
+}
+
+// 订阅配置变更，只有新配置校验通过之后才会通知
+func (m *Manager) Subscribe(fn func(old, cur *Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// 监听配置文件变更，k8s 中 ConfigMap 更新之后不需要重启 pod
+func (m *Manager) Watch() {
+	m.v.OnConfigChange(func(e fsnotify.Event) {
+		if err := m.reload(); err != nil {
+			// 新配置有问题时继续使用旧配置
+			log.Println("重新加载配置失败......", e.Name, err)
+		}
+	})
+	m.v.WatchConfig()
+}
+
+func (m *Manager) reload() error {
+	cfg, err := m.decode()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old := m.cur.Swap(cfg)
+	for _, fn := range m.subscribers {
+		fn(old, cfg)
+	}
+
+	return nil
+}
+
+func (m *Manager) decode() (*Config, error) {
+	var cfg Config
+	if err := m.v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// 所有的 key 都需要有默认值，否则 viper 不会用环境变量覆盖
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("http.addr", ":8080")
+	v.SetDefault("http.shutdownTimeout", 10*time.Second)
+	v.SetDefault("http.trustedProxies", []string{})
+	v.SetDefault("db.dns", "")
+	v.SetDefault("redis.addr", "")
+	v.SetDefault("sms.provider", "memory")
+	v.SetDefault("sms.failover.providers", []string{})
+	v.SetDefault("sms.failover.strategy", "ordered")
+	v.SetDefault("sms.failover.timeoutThreshold", 3)
+	v.SetDefault("sms.ratelimit.interval", time.Second)
+	v.SetDefault("sms.ratelimit.rate", 100)
+	v.SetDefault("sms.async.windowSize", 100)
+	v.SetDefault("sms.async.minSamples", 10)
+	v.SetDefault("sms.async.errRateThreshold", 0.3)
+	v.SetDefault("sms.async.latencyThreshold", 3*time.Second)
+	v.SetDefault("sms.async.workers", 2)
+	v.SetDefault("sms.async.pollInterval", time.Second)
+	v.SetDefault("sms.async.retryMax", 3)
+	v.SetDefault("sms.async.baseBackoff", 5*time.Second)
+	v.SetDefault("sms.async.maxBackoff", 5*time.Minute)
+	v.SetDefault("sms.async.sendTimeout", 10*time.Second)
+	v.SetDefault("sms.tencent.appId", "")
+	v.SetDefault("sms.tencent.signName", "")
+	v.SetDefault("sms.tencent.secretId", "")
+	v.SetDefault("sms.tencent.secretKey", "")
+	v.SetDefault("sms.tencent.region", "ap-guangzhou")
+	v.SetDefault("sms.aliyun.accessKeyId", "")
+	v.SetDefault("sms.aliyun.accessKeySecret", "")
+	v.SetDefault("sms.aliyun.signName", "")
+	v.SetDefault("sms.aliyun.regionId", "cn-hangzhou")
+	v.SetDefault("sms.aliyun.endpoint", "https://dysmsapi.aliyuncs.com")
+	v.SetDefault("sms.logHashKey", "")
+	v.SetDefault("sms.auth.key", "")
+	v.SetDefault("ratelimit.interval", time.Second)
+	v.SetDefault("ratelimit.rate", 100)
+	v.SetDefault("cors.allowOrigins", []string{"http://localhost"})
+	v.SetDefault("admin.uids", []int64{})
+	v.SetDefault("email.provider", "memory")
+	v.SetDefault("email.smtp.host", "")
+	v.SetDefault("email.smtp.port", 587)
+	v.SetDefault("email.smtp.username", "")
+	v.SetDefault("email.smtp.password", "")
+	v.SetDefault("email.smtp.from", "")
+	v.SetDefault("email.smtp.timeout", 10*time.Second)
+	v.SetDefault("codeCache.type", "redis")
+	v.SetDefault("codeCache.capacity", 100000)
+	v.SetDefault("loginLock.account.threshold", 5)
+	v.SetDefault("loginLock.account.baseLock", time.Minute)
+	v.SetDefault("loginLock.account.maxLock", time.Hour)
+	v.SetDefault("loginLock.account.window", 2*time.Hour)
+	v.SetDefault("loginLock.ip.threshold", 50)
+	v.SetDefault("loginLock.ip.baseLock", time.Minute)
+	v.SetDefault("loginLock.ip.maxLock", time.Hour)
+	v.SetDefault("loginLock.ip.window", 2*time.Hour)
+	v.SetDefault("wechat.appId", "")
+	v.SetDefault("wechat.appSecret", "")
+	v.SetDefault("wechat.redirectURL", "")
+	v.SetDefault("wechat.stateKey", "")
+	v.SetDefault("wechat.apiBase", "https://api.weixin.qq.com")
+	// 使用验证码的业务都需要配置策略
+	for _, biz := range []string{"login", "reset_password", "bind_phone", "bind_email", "unlock"} {
+		v.SetDefault("code."+biz+".length", 6)
+		v.SetDefault("code."+biz+".alphabet", "0123456789")
+		v.SetDefault("code."+biz+".ttl", 10*time.Minute)
+		v.SetDefault("code."+biz+".resendInterval", time.Minute)
+		v.SetDefault("code."+biz+".maxAttempts", 3)
+		// 验证码都使用 login_code 模板发送
+		v.SetDefault("sms.auth.bizTemplates."+biz, []string{"login_code"})
+	}
+}
+
+// 启动时校验配置，尽早暴露配置错误
+func (c *Config) Validate() error {
+	var errs []error
+	if c.HTTP.Addr == "" {
+		errs = append(errs, errors.New("http.addr 不能为空"))
+	}
+
+	if c.HTTP.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("http.shutdownTimeout 必须大于 0"))
+	}
+
+	for _, p := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			errs = append(errs, fmt.Errorf("http.trustedProxies 中的 %q 不是合法的 IP 或者 CIDR", p))
+		}
+	}
+
+	if c.DB.DNS == "" {
+		errs = append(errs, errors.New("db.dns 不能为空"))
+	}
+
+	if c.Redis.Addr == "" {
+		errs = append(errs, errors.New("redis.addr 不能为空"))
+	}
+
+	if c.JWT.Access.Current == "" || len(c.JWT.Access.Keys) == 0 {
+		errs = append(errs, errors.New("jwt.access 至少需要配置一个 key"))
+	}
+
+	if c.JWT.Refresh.Current == "" || len(c.JWT.Refresh.Keys) == 0 {
+		errs = append(errs, errors.New("jwt.refresh 至少需要配置一个 key"))
+	}
+
+	providers := c.SMS.Failover.Providers
+	if len(providers) == 0 {
+		providers = []string{c.SMS.Provider}
+	} else {
+		switch c.SMS.Failover.Strategy {
+		case "ordered":
+		case "timeout":
+			if c.SMS.Failover.TimeoutThreshold <= 0 {
+				errs = append(errs, errors.New("sms.failover.timeoutThreshold 必须大于 0"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("不支持的故障转移策略 %q", c.SMS.Failover.Strategy))
+		}
+	}
+
+	for _, provider := range providers {
+		errs = append(errs, c.validateSMSProvider(provider))
+	}
+
+	names := make(map[string]bool, len(c.SMS.Templates))
+	for _, tpl := range c.SMS.Templates {
+		if tpl.Name == "" || names[tpl.Name] {
+			errs = append(errs, fmt.Errorf("sms.templates 的 name %q 为空或者重复", tpl.Name))
+		}
+		names[tpl.Name] = true
+	}
+
+	if len(c.SMS.LogHashKey) < 32 {
+		errs = append(errs, errors.New("sms.logHashKey 至少 32 字节"))
+	}
+
+	if len(c.SMS.Auth.Key) < 32 {
+		errs = append(errs, errors.New("sms.auth.key 至少 32 字节"))
+	}
+
+	for biz, tpls := range c.SMS.Auth.BizTemplates {
+		for _, tpl := range tpls {
+			if !names[tpl] {
+				errs = append(errs, fmt.Errorf("sms.auth.bizTemplates 中 %s 使用的模板 %q 不存在", biz, tpl))
+			}
+		}
+	}
+
+	if c.SMS.RateLimit.Interval <= 0 || c.SMS.RateLimit.Rate <= 0 {
+		errs = append(errs, errors.New("sms.ratelimit.interval 与 sms.ratelimit.rate 必须大于 0"))
+	}
+
+	a := c.SMS.Async
+	if a.WindowSize <= 0 || a.Workers <= 0 || a.RetryMax <= 0 {
+		errs = append(errs, errors.New("sms.async 的 windowSize、workers、retryMax 必须大于 0"))
+	}
+
+	if a.ErrRateThreshold < 0 || a.ErrRateThreshold > 1 {
+		errs = append(errs, errors.New("sms.async.errRateThreshold 必须在 0 到 1 之间"))
+	}
+
+	if a.PollInterval <= 0 || a.SendTimeout <= 0 || a.BaseBackoff <= 0 || a.MaxBackoff < a.BaseBackoff {
+		errs = append(errs, errors.New("sms.async 的时间配置不正确"))
+	}
+
+	if c.RateLimit.Interval <= 0 || c.RateLimit.Rate <= 0 {
+		errs = append(errs, errors.New("ratelimit.interval 与 ratelimit.rate 必须大于 0"))
+	}
+
+	for biz, p := range c.Code {
+		if p.Length <= 0 || len([]rune(p.Alphabet)) < 2 || p.MaxAttempts <= 0 {
+			errs = append(errs, fmt.Errorf("code.%s 的 length、maxAttempts 必须大于 0，alphabet 至少 2 个字符", biz))
+		}
+
+		// Lua 脚本中按秒计算
+		if p.TTL < time.Second || p.ResendInterval < 0 || p.ResendInterval >= p.TTL {
+			errs = append(errs, fmt.Errorf("code.%s 的 ttl 至少 1s，resendInterval 必须小于 ttl", biz))
+		}
+	}
+
+	switch c.Email.Provider {
+	case "memory":
+	case "smtp":
+		s := c.Email.SMTP
+		if s.Host == "" || s.Port <= 0 || s.From == "" || s.Timeout <= 0 {
+			errs = append(errs, errors.New("email.smtp 配置不完整"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("不支持的邮件服务 %q", c.Email.Provider))
+	}
+
+	switch c.CodeCache.Type {
+	case "redis":
+	case "local":
+		if c.CodeCache.Capacity <= 0 {
+			errs = append(errs, errors.New("codeCache.capacity 必须大于 0"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("不支持的验证码缓存 %q", c.CodeCache.Type))
+	}
+
+	for scope, p := range map[string]LoginLockPolicyConfig{
+		"account": c.LoginLock.Account,
+		"ip":      c.LoginLock.IP,
+	} {
+		// Lua 脚本中按秒计算，失败记录至少要保留到锁定结束，否则锁定时长不会翻倍
+		if p.Threshold <= 0 || p.BaseLock < time.Second || p.MaxLock < p.BaseLock || p.Window < p.MaxLock {
+			errs = append(errs, fmt.Errorf("loginLock.%s 配置不正确，需要 threshold > 0 且 1s <= baseLock <= maxLock <= window", scope))
+		}
+	}
+
+	if w := c.Wechat; w.AppId != "" {
+		if w.AppSecret == "" || w.RedirectURL == "" || w.APIBase == "" {
+			errs = append(errs, errors.New("wechat 配置不完整"))
+		}
+
+		// HS512 的 key 至少 64 字节
+		if len(w.StateKey) < 64 {
+			errs = append(errs, errors.New("wechat.stateKey 至少 64 字节"))
+		}
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allowOrigins 不能为空"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateSMSProvider(provider string) error {
+	var errs []error
+	switch provider {
+	case "memory":
+	case "tencent":
+		t := c.SMS.Tencent
+		if t.AppId == "" || t.SignName == "" || t.SecretId == "" || t.SecretKey == "" {
+			errs = append(errs, errors.New("sms.tencent 配置不完整"))
+		}
+	case "aliyun":
+		a := c.SMS.Aliyun
+		if a.AccessKeyId == "" || a.AccessKeySecret == "" || a.SignName == "" || a.Endpoint == "" {
+			errs = append(errs, errors.New("sms.aliyun 配置不完整"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("不支持的短信服务商 %q", provider))
+	}
+
+	// memory 不需要模板 id，其他服务商每个模板都要配置自己的模板 id
+	if provider != "memory" {
+		for _, tpl := range c.SMS.Templates {
+			if tpl.Ids[provider] == "" {
+				errs = append(errs, fmt.Errorf("sms.templates 的 %s 没有配置 %s 的模板 id", tpl.Name, provider))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
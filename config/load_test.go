@@ -0,0 +1,219 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+db:
+  dns: "root:root@tcp(localhost:13316)/webook"
+redis:
+  addr: "localhost:6379"
+jwt:
+  access:
+    current: "at-1"
+    keys:
+      - id: "at-1"
+        method: "HS512"
+        secret: "at-secret"
+  refresh:
+    current: "rt-1"
+    keys:
+      - id: "rt-1"
+        method: "HS512"
+        secret: "rt-secret"
+sms:
+  logHashKey: "test-sms-log-hash-key-0123456789"
+  auth:
+    key: "test-sms-auth-key-0123456789abcdef"
+  templates:
+    - name: "login_code"
+      params: ["code"]
+ratelimit:
+  interval: "2s"
+`
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webook.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testConfig), 0600))
+
+	testCases := []struct {
+		name string
+
+		env  map[string]string
+		args []string
+
+		wantDNS     string
+		wantRedis   string
+		wantRate    int
+		wantHashKey string
+	}{
+		{
+			name:        "配置文件与默认值",
+			args:        []string{"--config", file},
+			wantDNS:     "root:root@tcp(localhost:13316)/webook",
+			wantRedis:   "localhost:6379",
+			wantRate:    100,
+			wantHashKey: "test-sms-log-hash-key-0123456789",
+		},
+		{
+			name: "环境变量覆盖配置文件",
+			env: map[string]string{
+				"WEBOOK_REDIS_ADDR":     "redis:6379",
+				"WEBOOK_RATELIMIT_RATE": "10",
+				"WEBOOK_SMS_LOGHASHKEY": "env-sms-log-hash-key-0123456789ab",
+			},
+			args:        []string{"--config", file},
+			wantDNS:     "root:root@tcp(localhost:13316)/webook",
+			wantRedis:   "redis:6379",
+			wantRate:    10,
+			wantHashKey: "env-sms-log-hash-key-0123456789ab",
+		},
+		{
+			name: "命令行参数覆盖环境变量",
+			env: map[string]string{
+				"WEBOOK_REDIS_ADDR": "redis:6379",
+			},
+			args:        []string{"--config", file, "--redis.addr", "flag-redis:6379", "--db.dns", "flag-dns"},
+			wantDNS:     "flag-dns",
+			wantRedis:   "flag-redis:6379",
+			wantRate:    100,
+			wantHashKey: "test-sms-log-hash-key-0123456789",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load(tc.args)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDNS, cfg.DB.DNS)
+			assert.Equal(t, tc.wantRedis, cfg.Redis.Addr)
+			assert.Equal(t, tc.wantRate, cfg.RateLimit.Rate)
+			assert.Equal(t, 2*time.Second, cfg.RateLimit.Interval)
+			assert.Equal(t, "memory", cfg.SMS.Provider)
+			assert.Equal(t, "at-secret", cfg.JWT.Access.Keys[0].Secret)
+			assert.Equal(t, tc.wantHashKey, cfg.SMS.LogHashKey)
+			assert.Equal(t, []string{"login_code"}, cfg.SMS.Auth.BizTemplates["reset_password"])
+		})
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webook.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testConfig), 0600))
+
+	// 选择腾讯云但是没有配置密钥
+	_, err := Load([]string{"--config", file, "--sms.provider", "tencent"})
+	assert.ErrorContains(t, err, "sms.tencent 配置不完整")
+
+	// 故障转移中的服务商同样需要校验
+	t.Setenv("WEBOOK_SMS_FAILOVER_PROVIDERS", "memory,unknown")
+	_, err = Load([]string{"--config", file})
+	assert.ErrorContains(t, err, `不支持的短信服务商 "unknown"`)
+
+	// 手机号哈希的 key 太短
+	t.Setenv("WEBOOK_SMS_LOGHASHKEY", "short")
+	_, err = Load([]string{"--config", file})
+	assert.ErrorContains(t, err, "sms.logHashKey 至少 32 字节")
+
+	_, err = Load([]string{"--config", filepath.Join(t.TempDir(), "not-exist.yaml")})
+	assert.Error(t, err)
+}
+
+func TestManager_Watch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webook.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testConfig), 0600))
+
+	m, err := NewManager([]string{"--config", file})
+	require.NoError(t, err)
+	assert.Equal(t, 100, m.Config().RateLimit.Rate)
+
+	type change struct {
+		old, cur *Config
+	}
+	changes := make(chan change, 1)
+	m.Subscribe(func(old, cur *Config) {
+		// 一次写文件可能触发多个事件，只关心第一次变更
+		select {
+		case changes <- change{old: old, cur: cur}:
+		default:
+		}
+	})
+	m.Watch()
+
+	// 校验不通过的配置不会生效，也不会通知订阅者
+	invalid := strings.Replace(testConfig, "sms:\n", "sms:\n  provider: \"unknown\"\n", 1)
+	require.NoError(t, os.WriteFile(file, []byte(invalid), 0600))
+	valid := strings.Replace(testConfig, `interval: "2s"`, "interval: \"2s\"\n  rate: 10", 1)
+	require.NoError(t, os.WriteFile(file, []byte(valid), 0600))
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, 100, c.old.RateLimit.Rate)
+		assert.Equal(t, 10, c.cur.RateLimit.Rate)
+		assert.Equal(t, 10, m.Config().RateLimit.Rate)
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到配置变更通知")
+	}
+}
+
+func TestLoad_CodePolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := testConfig + `
+code:
+  login:
+    length: 8
+    alphabet: "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cfg, err := Load([]string{"--config=" + path})
+	require.NoError(t, err)
+	// 只覆盖配置了的字段，其余使用默认值
+	assert.Equal(t, CodePolicyConfig{
+		Length:         8,
+		Alphabet:       "ABCDEFGHJKLMNPQRSTUVWXYZ23456789",
+		TTL:            10 * time.Minute,
+		ResendInterval: time.Minute,
+		MaxAttempts:    3,
+	}, cfg.Code["login"])
+	assert.Contains(t, cfg.Code, "reset_password")
+	assert.Contains(t, cfg.Code, "bind_phone")
+	assert.Contains(t, cfg.Code, "bind_email")
+
+	content += `
+  bind_phone:
+    ttl: "1m"
+    resendInterval: "1m"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	_, err = Load([]string{"--config=" + path})
+	assert.ErrorContains(t, err, "code.bind_phone 的 ttl 至少 1s，resendInterval 必须小于 ttl")
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webook.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testConfig), 0600))
+
+	cfg, err := Load([]string{"--config", file})
+	require.NoError(t, err)
+	// 默认不信任任何代理
+	assert.Empty(t, cfg.HTTP.TrustedProxies)
+
+	content := testConfig + "http:\n  trustedProxies: [\"10.244.0.0/16\", \"not-an-ip\"]\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	_, err = Load([]string{"--config", file})
+	assert.ErrorContains(t, err, `http.trustedProxies 中的 "not-an-ip" 不是合法的 IP 或者 CIDR`)
+}
@@ -1,5 +1,17 @@
 package config
 
+import "time"
+
+// HTTP 服务配置
+type HTTPConfig struct {
+	Addr string
+	// 优雅退出时等待正在处理的请求完成的最长时间
+	ShutdownTimeout time.Duration
+	// 可信的反向代理(IP 或者 CIDR)，只有来自这些地址的 X-Forwarded-For 才会被采用，
+	// 为空时直接使用连接的对端地址作为客户端 IP
+	TrustedProxies []string
+}
+
 // 数据库配置
 type DBConfig struct {
 	DNS string
@@ -10,8 +22,225 @@ type RedisConfig struct {
 	Addr string
 }
 
+// JWT 签名 key 配置
+type JWTKeyConfig struct {
+	// 写入 token header 中的 kid
+	Id string
+	// 签名算法：HS512、RS256、EdDSA
+	Method string
+	// HS512 使用的密钥，生产环境使用 SecretFile 从挂载的 k8s Secret 中读取，
+	// 两者都配置时使用 SecretFile
+	Secret     string
+	SecretFile string
+	// RS256、EdDSA 使用的 PEM 文件，只用于校验的旧 key 可以只配置公钥
+	PrivateKeyFile string
+	PublicKeyFile  string
+}
+
+// 一组 JWT key，Current 用于签名，Keys 中的所有 key 都可以用于校验
+// 轮换时新增一个 key 并修改 Current，旧 key 保留到其签发的 token 全部过期
+type JWTKeySetConfig struct {
+	Current string
+	Keys    []JWTKeyConfig
+}
+
+// JWT 配置，access token 与 refresh token 使用不同的 key
+type JWTConfig struct {
+	Access  JWTKeySetConfig
+	Refresh JWTKeySetConfig
+}
+
+// 腾讯云短信配置
+type TencentSMSConfig struct {
+	AppId     string
+	SignName  string
+	SecretId  string
+	SecretKey string
+	Region    string
+}
+
+// 阿里云短信配置
+type AliyunSMSConfig struct {
+	AccessKeyId     string
+	AccessKeySecret string
+	SignName        string
+	RegionId        string
+	Endpoint        string
+}
+
+// 短信服务商故障转移配置
+type SMSFailoverConfig struct {
+	// 按照优先级排列的服务商，为空时只使用 Provider
+	Providers []string
+	// ordered：出错之后依次尝试下一个服务商；timeout：连续超时之后切换服务商
+	Strategy string
+	// timeout 策略下连续超时超过多少次切换服务商
+	TimeoutThreshold int32
+}
+
+// 异步短信配置，服务商不健康时转为异步发送
+type SMSAsyncConfig struct {
+	// 最近多少次发送结果用于判断服务商是否健康
+	WindowSize int
+	// 样本数少于这个值时认为服务商健康
+	MinSamples int
+	// 错误率超过阈值认为服务商不健康，0-1
+	ErrRateThreshold float64
+	// 平均响应时间超过阈值认为服务商不健康
+	LatencyThreshold time.Duration
+	// 后台重试的 worker 数量
+	Workers int
+	// 没有待发送的短信时，轮询数据库的间隔
+	PollInterval time.Duration
+	// 每条短信最多尝试发送的次数
+	RetryMax int
+	// 重试间隔从 BaseBackoff 开始翻倍，最多 MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// 后台单次发送的超时时间
+	SendTimeout time.Duration
+}
+
+// 短信模板配置，同一个模板在不同服务商有不同的模板 id
+type SMSTemplateConfig struct {
+	// 逻辑名称，例如 login_code
+	Name string
+	// 参数名，腾讯云按照这个顺序传递参数
+	Params []string
+	// 服务商 → 模板 id，例如 tencent: "1877556"
+	Ids map[string]string
+}
+
+// 短信配置
+type SMSConfig struct {
+	// 使用的短信服务商：memory、tencent、aliyun
+	Provider  string
+	Failover  SMSFailoverConfig
+	Async     SMSAsyncConfig
+	Templates []SMSTemplateConfig
+	// 所有实例发送短信的总速率，不能超过服务商的配额
+	RateLimit RateLimitConfig
+	Tencent   TencentSMSConfig
+	Aliyun    AliyunSMSConfig
+	// 发送记录中手机号哈希使用的 HMAC key，修改之后旧记录无法再按手机号查询
+	LogHashKey string
+	Auth       SMSAuthConfig
+}
+
+// 短信调用方认证配置，调用方使用 Key 签名的 token 代替模板名称
+type SMSAuthConfig struct {
+	// HMAC key，与调用方共享
+	Key string
+	// 业务 → 允许使用的模板名称，业务名称不区分大小写
+	BizTemplates map[string][]string
+}
+
+// 限流配置，Interval 内最多 Rate 个请求
+type RateLimitConfig struct {
+	Interval time.Duration
+	Rate     int
+}
+
+// 跨域配置
+type CORSConfig struct {
+	// origin 中包含任意一项即允许跨域，例如 http://localhost、company.com
+	AllowOrigins []string
+}
+
 // 全局配置
-type config struct {
-	DB    DBConfig
-	Redis RedisConfig
+// SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// 发件人地址
+	From string
+	// 连接与发送一封邮件的最长时间，请求的 ctx 没有 deadline 时使用
+	Timeout time.Duration
+}
+
+// 邮件配置
+type EmailConfig struct {
+	// 使用的邮件服务：memory、smtp
+	Provider string
+	SMTP     SMTPConfig
+}
+
+// 验证码策略配置
+type CodePolicyConfig struct {
+	// 验证码长度
+	Length int
+	// 验证码使用的字符
+	Alphabet string
+	// 有效期，按秒计算
+	TTL time.Duration
+	// 两次发送之间的最小间隔，按秒计算
+	ResendInterval time.Duration
+	// 一个验证码最多可以验证几次
+	MaxAttempts int
+}
+
+// 验证码缓存配置
+type CodeCacheConfig struct {
+	// redis：多个实例共享验证码；local：进程内缓存，用于本地开发与单机部署
+	Type string
+	// local 最多缓存的验证码数量
+	Capacity int
+}
+
+// 登录失败锁定策略
+type LoginLockPolicyConfig struct {
+	// 连续失败多少次之后锁定
+	Threshold int
+	// 第一次锁定的时长，之后每次锁定翻倍
+	BaseLock time.Duration
+	// 锁定时长的上限
+	MaxLock time.Duration
+	// 失败记录的保留时间，期间没有再失败则重新计数
+	Window time.Duration
+}
+
+// 密码登录的防暴力破解配置
+type LoginLockConfig struct {
+	// 同一个账号的失败次数
+	Account LoginLockPolicyConfig
+	// 同一个 IP 的失败次数，撞库时一个 IP 会尝试很多账号
+	IP LoginLockPolicyConfig
+}
+
+// 微信扫码登录配置
+type WechatConfig struct {
+	// 微信开放平台网站应用的 AppID 与 AppSecret，为空时不启用微信登录
+	AppId     string
+	AppSecret string
+	// 扫码之后跳转回来的地址，需要与开放平台上配置的域名一致
+	RedirectURL string
+	// 签名 state cookie 使用的 key，多个实例需要一致
+	StateKey string
+	// 微信接口地址，本地开发时可以指向假的服务
+	APIBase string
+}
+
+// 管理员配置
+type AdminConfig struct {
+	// 可以访问 /admin 接口的用户 id
+	Uids []int64
+}
+
+type Config struct {
+	HTTP      HTTPConfig
+	DB        DBConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	SMS       SMSConfig
+	Email     EmailConfig
+	RateLimit RateLimitConfig
+	CORS      CORSConfig
+	Admin     AdminConfig
+	// 业务 → 验证码策略
+	Code      map[string]CodePolicyConfig
+	CodeCache CodeCacheConfig
+	LoginLock LoginLockConfig
+	Wechat    WechatConfig
 }
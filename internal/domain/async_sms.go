@@ -0,0 +1,24 @@
+package domain
+
+// AsyncSms 等待异步发送的短信
+type AsyncSms struct {
+	Id int64
+	// 发送短信的业务，用于审计
+	Biz string
+	// 短信模板的逻辑名称
+	Tpl string
+	// 参数名 → 参数值
+	Args    map[string]string
+	Numbers []string
+	// 已经尝试发送的次数
+	RetryCnt int
+	// 最多尝试发送的次数
+	RetryMax int
+}
+
+// AsyncSmsStats 异步短信各个状态的数量，用于监控
+type AsyncSmsStats struct {
+	Waiting int64 `json:"waiting"`
+	Success int64 `json:"success"`
+	Failed  int64 `json:"failed"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CodePolicy 验证码策略，每个业务可以不同
+type CodePolicy struct {
+	// 验证码长度
+	Length int
+	// 验证码使用的字符
+	Alphabet string
+	// 有效期
+	TTL time.Duration
+	// 两次发送之间的最小间隔
+	ResendInterval time.Duration
+	// 一个验证码最多可以验证几次
+	MaxAttempts int
+}
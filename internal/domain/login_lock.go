@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// LoginLockPolicy 密码登录连续失败之后的锁定策略
+type LoginLockPolicy struct {
+	// 连续失败多少次之后锁定
+	Threshold int
+	// 第一次锁定的时长，之后每次锁定翻倍
+	BaseLock time.Duration
+	// 锁定时长的上限
+	MaxLock time.Duration
+	// 失败记录的保留时间
+	Window time.Duration
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+type SmsStatus uint8
+
+const (
+	SmsStatusUnknown SmsStatus = iota
+	SmsStatusSuccess
+	SmsStatusFailed
+)
+
+func (s SmsStatus) String() string {
+	switch s {
+	case SmsStatusSuccess:
+		return "success"
+	case SmsStatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// SmsLog 一次短信发送记录，每个手机号一条
+type SmsLog struct {
+	Id  int64
+	Biz string
+	// 写入时是完整的手机号，读出来是脱敏之后的手机号
+	Phone    string
+	Tpl      string
+	Provider string
+	Status   SmsStatus
+	// 服务商返回的信息，失败时是错误信息
+	Message string
+	Latency time.Duration
+	Ctime   time.Time
+}
+
+// SmsLogQuery 查询短信发送记录，Phone 与时间范围至少指定一个
+type SmsLogQuery struct {
+	Phone string
+	// [Start, End)，零值表示不限制
+	Start  time.Time
+	End    time.Time
+	Offset int
+	Limit  int
+}
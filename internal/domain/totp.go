@@ -0,0 +1,11 @@
+package domain
+
+// Totp 用户的两步验证密钥
+type Totp struct {
+	Uid    int64
+	Secret string
+	// 校验过验证码之后才会启用
+	Enabled bool
+	// 最近一次使用的时间步长
+	LastStep int64
+}
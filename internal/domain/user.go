@@ -8,5 +8,21 @@ type User struct {
 	Email    string
 	Password string
 	Phone    string
-	Ctime    time.Time
+	// 微信登录的用户才有
+	WechatInfo WechatInfo
+
+	// 非敏感信息，用户可自行编辑
+	Nickname string
+	Birthday time.Time
+	AboutMe  string
+
+	Ctime time.Time
+}
+
+// WechatInfo 微信 OAuth2 返回的用户标识
+type WechatInfo struct {
+	// 用户在当前应用下的唯一标识
+	OpenId string
+	// 用户在同一个开放平台账号下所有应用的唯一标识，可能为空
+	UnionId string
 }
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+)
+
+var ErrWaitingSMSNotFound = dao.ErrWaitingSMSNotFound
+
+type AsyncSmsRepository interface {
+	Add(ctx context.Context, s domain.AsyncSms) error
+	// 抢占一条等待发送的短信，lease 时间内其他实例不会再抢到它
+	PreemptWaitingSMS(ctx context.Context, lease time.Duration) (domain.AsyncSms, error)
+	// 发送结束之后不再保存模板参数，例如验证码
+	ReportSuccess(ctx context.Context, s domain.AsyncSms) error
+	// 只有 s.Numbers 会在下一次重试时发送，部分号码发送成功之后不会重复发送
+	ReportRetry(ctx context.Context, s domain.AsyncSms, nextTime time.Time) error
+	ReportFailed(ctx context.Context, s domain.AsyncSms) error
+	Stats(ctx context.Context) (domain.AsyncSmsStats, error)
+}
+
+// 使用数据库保存异步短信，多个实例共享同一个队列
+type DBAsyncSmsRepository struct {
+	dao dao.AsyncSmsDAO
+}
+
+func NewAsyncSmsRepository(dao dao.AsyncSmsDAO) AsyncSmsRepository {
+	return &DBAsyncSmsRepository{
+		dao: dao,
+	}
+}
+
+func (r *DBAsyncSmsRepository) Add(ctx context.Context, s domain.AsyncSms) error {
+	cfg, err := r.config(s)
+	if err != nil {
+		return err
+	}
+
+	return r.dao.Insert(ctx, dao.AsyncSms{
+		Config:   cfg,
+		RetryMax: s.RetryMax,
+	})
+}
+
+func (r *DBAsyncSmsRepository) PreemptWaitingSMS(ctx context.Context, lease time.Duration) (domain.AsyncSms, error) {
+	s, err := r.dao.Preempt(ctx, lease)
+	if err != nil {
+		return domain.AsyncSms{}, err
+	}
+
+	var cfg dao.AsyncSmsConfig
+	if err = json.Unmarshal([]byte(s.Config), &cfg); err != nil {
+		return domain.AsyncSms{}, err
+	}
+
+	return domain.AsyncSms{
+		Id:       s.Id,
+		Biz:      cfg.Biz,
+		Tpl:      cfg.Tpl,
+		Args:     cfg.Args,
+		Numbers:  cfg.Numbers,
+		RetryCnt: s.RetryCnt,
+		RetryMax: s.RetryMax,
+	}, nil
+}
+
+func (r *DBAsyncSmsRepository) ReportSuccess(ctx context.Context, s domain.AsyncSms) error {
+	s.Args = nil
+	cfg, err := r.config(s)
+	if err != nil {
+		return err
+	}
+
+	return r.dao.MarkSuccess(ctx, s.Id, cfg)
+}
+
+func (r *DBAsyncSmsRepository) ReportRetry(ctx context.Context, s domain.AsyncSms, nextTime time.Time) error {
+	cfg, err := r.config(s)
+	if err != nil {
+		return err
+	}
+
+	return r.dao.MarkRetry(ctx, s.Id, cfg, nextTime.UnixMilli())
+}
+
+func (r *DBAsyncSmsRepository) ReportFailed(ctx context.Context, s domain.AsyncSms) error {
+	s.Args = nil
+	cfg, err := r.config(s)
+	if err != nil {
+		return err
+	}
+
+	return r.dao.MarkFailed(ctx, s.Id, cfg)
+}
+
+func (r *DBAsyncSmsRepository) Stats(ctx context.Context) (domain.AsyncSmsStats, error) {
+	cnts, err := r.dao.CountByStatus(ctx)
+	if err != nil {
+		return domain.AsyncSmsStats{}, err
+	}
+
+	return domain.AsyncSmsStats{
+		Waiting: cnts[dao.AsyncStatusWaiting],
+		Success: cnts[dao.AsyncStatusSuccess],
+		Failed:  cnts[dao.AsyncStatusFailed],
+	}, nil
+}
+
+func (r *DBAsyncSmsRepository) config(s domain.AsyncSms) (string, error) {
+	cfg, err := json.Marshal(dao.AsyncSmsConfig{
+		Biz:     s.Biz,
+		Tpl:     s.Tpl,
+		Args:    s.Args,
+		Numbers: s.Numbers,
+	})
+	return string(cfg), err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"webook/internal/domain"
 
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
@@ -17,15 +18,24 @@ var luaSetCode string
 //go:embed lua/verify_code.lua
 var luaVerifyCode string
 
+//go:embed lua/delete_code.lua
+var luaDeleteCode string
+
 var (
 	ErrCodeSendTooFrequently  = errors.New("code send too frequently")
 	ErrCodeVerifyTooManyTimes = errors.New("code verify too many")
+	ErrCodeVerifyFailed       = errors.New("code verify failed")
 	ErrUnknowForCode          = errors.New("unknow code")
 )
 
+// CodeCache 保存验证码，channel 是验证码的发送渠道，例如 phone、email，
+// target 是渠道下的接收方，例如手机号、邮箱
 type CodeCache interface {
-	SetCode(ctx context.Context, biz string, phone string, code string) error
-	VerifyCode(ctx context.Context, biz string, phone string, inputCode string) error
+	// SetCode 按照 policy 设置验证码的有效期、重新发送间隔与验证次数
+	SetCode(ctx context.Context, channel, biz, target, code string, policy domain.CodePolicy) error
+	VerifyCode(ctx context.Context, channel, biz, target, inputCode string) error
+	// DeleteCode 删除验证码，只有当前保存的还是 code 时才会删除
+	DeleteCode(ctx context.Context, channel, biz, target, code string) error
 }
 
 type RedisCodeCache struct {
@@ -36,14 +46,16 @@ func NewCodeCache(client redis.Cmdable) CodeCache {
 	return &RedisCodeCache{client: client}
 }
 
-func (c *RedisCodeCache) key(biz string, phone string) string {
-	return fmt.Sprintf("phone_code:%s:%s", biz, phone)
+// 例如 phone_code:login:152xxxxxxxx、email_code:login:xxx@qq.com
+func codeKey(channel, biz, target string) string {
+	return fmt.Sprintf("%s_code:%s:%s", channel, biz, target)
 }
 
-func (c *RedisCodeCache) SetCode(ctx context.Context, biz string, phone string,
-	code string) error {
-	key := c.key(biz, phone)
-	res, err := c.client.Eval(ctx, luaSetCode, []string{key}, code).Int()
+func (c *RedisCodeCache) SetCode(ctx context.Context, channel, biz, target, code string,
+	policy domain.CodePolicy) error {
+	key := codeKey(channel, biz, target)
+	res, err := c.client.Eval(ctx, luaSetCode, []string{key}, code,
+		int64(policy.TTL.Seconds()), int64(policy.ResendInterval.Seconds()), policy.MaxAttempts).Int()
 	if err != nil {
 		return err
 	}
@@ -59,8 +71,8 @@ func (c *RedisCodeCache) SetCode(ctx context.Context, biz string, phone string,
 	}
 }
 
-func (c *RedisCodeCache) VerifyCode(ctx context.Context, biz string, phone string, inputCode string) error {
-	res, err := c.client.Eval(ctx, luaVerifyCode, []string{c.key(biz, phone)}, inputCode).Int()
+func (c *RedisCodeCache) VerifyCode(ctx context.Context, channel, biz, target, inputCode string) error {
+	res, err := c.client.Eval(ctx, luaVerifyCode, []string{codeKey(channel, biz, target)}, inputCode).Int()
 	if err != nil {
 		return err
 	}
@@ -72,9 +84,12 @@ func (c *RedisCodeCache) VerifyCode(ctx context.Context, biz string, phone strin
 		// 验证次数太多, 如果频繁出现这个错误，需要进行警告
 		return ErrCodeVerifyTooManyTimes
 	case -2:
-		// 验证码不存在
-		return ErrUnknowForCode
-
+		// 验证码错误或者不存在
+		return ErrCodeVerifyFailed
 	}
 	return ErrUnknowForCode
 }
+
+func (c *RedisCodeCache) DeleteCode(ctx context.Context, channel, biz, target, code string) error {
+	return c.client.Eval(ctx, luaDeleteCode, []string{codeKey(channel, biz, target)}, code).Err()
+}
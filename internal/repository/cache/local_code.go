@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+	"webook/internal/domain"
+)
+
+// LocalCodeCache 进程内的验证码缓存，用于本地开发与单机部署，
+// 行为与 set_code.lua、verify_code.lua 保持一致。容量满了之后淘汰最久没有使用的验证码
+type LocalCodeCache struct {
+	mu       sync.Mutex
+	capacity int
+	// 队头是最近使用的
+	lru   *list.List
+	items map[string]*list.Element
+	now   func() time.Time
+}
+
+type localCodeItem struct {
+	key  string
+	code string
+	// 剩余验证次数，验证成功之后为 -1
+	cnt      int
+	expireAt time.Time
+}
+
+func NewLocalCodeCache(capacity int) CodeCache {
+	return &LocalCodeCache{
+		capacity: capacity,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element, capacity),
+		now:      time.Now,
+	}
+}
+
+func (c *LocalCodeCache) SetCode(ctx context.Context, channel, biz, target, code string,
+	policy domain.CodePolicy) error {
+	// 与 Lua 脚本一样按秒计算
+	expiration := policy.TTL.Truncate(time.Second)
+	interval := policy.ResendInterval.Truncate(time.Second)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	key := codeKey(channel, biz, target)
+	if item, ok := c.get(key, now); ok && item.expireAt.Sub(now) >= expiration-interval {
+		// 发送太频繁
+		return ErrCodeSendTooFrequently
+	}
+
+	c.put(&localCodeItem{
+		key:      key,
+		code:     code,
+		cnt:      policy.MaxAttempts,
+		expireAt: now.Add(expiration),
+	})
+	return nil
+}
+
+func (c *LocalCodeCache) VerifyCode(ctx context.Context, channel, biz, target, inputCode string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.get(codeKey(channel, biz, target), c.now())
+	switch {
+	case !ok:
+		// 验证码不存在或者已经过期
+		return ErrCodeVerifyFailed
+	case item.cnt <= 0:
+		// 验证次数用完或者已经用过了
+		return ErrCodeVerifyTooManyTimes
+	case item.code == inputCode:
+		// 用完，不能再用了
+		item.cnt = -1
+		return nil
+	default:
+		item.cnt--
+		return ErrCodeVerifyFailed
+	}
+}
+
+func (c *LocalCodeCache) DeleteCode(ctx context.Context, channel, biz, target, code string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[codeKey(channel, biz, target)]
+	if ok && elem.Value.(*localCodeItem).code == code {
+		c.remove(elem)
+	}
+	return nil
+}
+
+// 取出没有过期的验证码，并标记为最近使用
+func (c *LocalCodeCache) get(key string, now time.Time) (*localCodeItem, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := elem.Value.(*localCodeItem)
+	if !now.Before(item.expireAt) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return item, true
+}
+
+func (c *LocalCodeCache) put(item *localCodeItem) {
+	if elem, ok := c.items[item.key]; ok {
+		elem.Value = item
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.items[item.key] = c.lru.PushFront(item)
+	for c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *LocalCodeCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.items, elem.Value.(*localCodeItem).key)
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"webook/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicy = domain.CodePolicy{
+	Length:         6,
+	Alphabet:       "0123456789",
+	TTL:            10 * time.Minute,
+	ResendInterval: time.Minute,
+	MaxAttempts:    3,
+}
+
+// 可以手动调整时间的本地缓存
+func newTestLocalCodeCache(capacity int) (*LocalCodeCache, *time.Time) {
+	now := time.Now()
+	c := NewLocalCodeCache(capacity).(*LocalCodeCache)
+	c.now = func() time.Time {
+		return now
+	}
+	return c, &now
+}
+
+func TestLocalCodeCache_SetCode(t *testing.T) {
+	ctx := context.Background()
+	c, now := newTestLocalCodeCache(10)
+
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13812345678", "123456", testPolicy))
+	// 一分钟之内不能重新发送
+	*now = now.Add(59 * time.Second)
+	assert.Equal(t, ErrCodeSendTooFrequently, c.SetCode(ctx, "phone", "login", "13812345678", "654321", testPolicy))
+	// 不同业务互不影响
+	assert.NoError(t, c.SetCode(ctx, "phone", "bind_phone", "13812345678", "111111", testPolicy))
+
+	*now = now.Add(2 * time.Second)
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13812345678", "654321", testPolicy))
+	// 新的验证码覆盖旧的验证码，验证次数也重置
+	assert.Equal(t, ErrCodeVerifyFailed, c.VerifyCode(ctx, "phone", "login", "13812345678", "123456"))
+	assert.NoError(t, c.VerifyCode(ctx, "phone", "login", "13812345678", "654321"))
+}
+
+func TestLocalCodeCache_DeleteCode(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestLocalCodeCache(10)
+
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13812345678", "123456", testPolicy))
+	// 不是自己设置的验证码不会删除
+	assert.NoError(t, c.DeleteCode(ctx, "phone", "login", "13812345678", "654321"))
+	assert.Equal(t, ErrCodeSendTooFrequently, c.SetCode(ctx, "phone", "login", "13812345678", "654321", testPolicy))
+
+	// 删除之后可以立刻重新发送，旧的验证码不能再用
+	assert.NoError(t, c.DeleteCode(ctx, "phone", "login", "13812345678", "123456"))
+	assert.Equal(t, ErrCodeVerifyFailed, c.VerifyCode(ctx, "phone", "login", "13812345678", "123456"))
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13812345678", "654321", testPolicy))
+}
+
+func TestLocalCodeCache_VerifyCode(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		// 依次输入的验证码
+		inputs  []string
+		wantErr []error
+		// 输入之前经过的时间
+		after time.Duration
+	}{
+		{
+			name:    "验证成功之后不能再用",
+			inputs:  []string{"123456", "123456"},
+			wantErr: []error{nil, ErrCodeVerifyTooManyTimes},
+		},
+		{
+			name:    "输错之后还可以重试",
+			inputs:  []string{"000000", "111111", "123456"},
+			wantErr: []error{ErrCodeVerifyFailed, ErrCodeVerifyFailed, nil},
+		},
+		{
+			name:    "输错次数用完",
+			inputs:  []string{"000000", "111111", "222222", "123456"},
+			wantErr: []error{ErrCodeVerifyFailed, ErrCodeVerifyFailed, ErrCodeVerifyFailed, ErrCodeVerifyTooManyTimes},
+		},
+		{
+			name:    "验证码过期",
+			inputs:  []string{"123456"},
+			wantErr: []error{ErrCodeVerifyFailed},
+			after:   10 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, now := newTestLocalCodeCache(10)
+			assert.NoError(t, c.SetCode(ctx, "phone", "login", "13812345678", "123456", testPolicy))
+
+			*now = now.Add(tc.after)
+			for i, input := range tc.inputs {
+				assert.Equal(t, tc.wantErr[i], c.VerifyCode(ctx, "phone", "login", "13812345678", input))
+			}
+		})
+	}
+}
+
+func TestLocalCodeCache_Evict(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestLocalCodeCache(2)
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13800000001", "111111", testPolicy))
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13800000002", "222222", testPolicy))
+	// 访问之后 13800000001 变成最近使用的
+	assert.Equal(t, ErrCodeVerifyFailed, c.VerifyCode(ctx, "phone", "login", "13800000001", "000000"))
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13800000003", "333333", testPolicy))
+
+	assert.Equal(t, ErrCodeVerifyFailed, c.VerifyCode(ctx, "phone", "login", "13800000002", "222222"))
+	assert.NoError(t, c.VerifyCode(ctx, "phone", "login", "13800000001", "111111"))
+	assert.NoError(t, c.VerifyCode(ctx, "phone", "login", "13800000003", "333333"))
+}
+
+// 并发验证同一个验证码，只有一个能成功
+func TestLocalCodeCache_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	c := NewLocalCodeCache(100)
+	assert.NoError(t, c.SetCode(ctx, "phone", "login", "13812345678", "123456", testPolicy))
+
+	var wg sync.WaitGroup
+	var success atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c.VerifyCode(ctx, "phone", "login", "13812345678", "123456") == nil {
+				success.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), success.Load())
+}
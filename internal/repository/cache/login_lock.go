@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+	"webook/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/login_fail.lua
+var luaLoginFail string
+
+// LoginLockCache 记录密码登录的失败次数，scope 是计数的维度，例如 account、ip
+type LoginLockCache interface {
+	// LockTTL 返回剩余的锁定时间，没有锁定返回 0
+	LockTTL(ctx context.Context, scope, target string) (time.Duration, error)
+	// Fail 记录一次失败，达到阈值之后锁定，返回本次锁定的时长，没有锁定返回 0
+	Fail(ctx context.Context, scope, target string, policy domain.LoginLockPolicy) (time.Duration, error)
+	// Reset 清空失败记录并解除锁定
+	Reset(ctx context.Context, scope, target string) error
+}
+
+type RedisLoginLockCache struct {
+	client redis.Cmdable
+}
+
+func NewLoginLockCache(client redis.Cmdable) LoginLockCache {
+	return &RedisLoginLockCache{client: client}
+}
+
+// 例如 login_fail:account:xxx@qq.com、login_fail:ip:127.0.0.1
+func loginFailKey(scope, target string) string {
+	return fmt.Sprintf("login_fail:%s:%s", scope, target)
+}
+
+func loginLockKey(scope, target string) string {
+	return fmt.Sprintf("login_lock:%s:%s", scope, target)
+}
+
+func (c *RedisLoginLockCache) LockTTL(ctx context.Context, scope, target string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, loginLockKey(scope, target)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// key 不存在时返回负数
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (c *RedisLoginLockCache) Fail(ctx context.Context, scope, target string,
+	policy domain.LoginLockPolicy) (time.Duration, error) {
+	lock, err := c.client.Eval(ctx, luaLoginFail,
+		[]string{loginFailKey(scope, target), loginLockKey(scope, target)},
+		policy.Threshold, int64(policy.BaseLock.Seconds()), int64(policy.MaxLock.Seconds()),
+		int64(policy.Window.Seconds())).Int64()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(lock) * time.Second, nil
+}
+
+func (c *RedisLoginLockCache) Reset(ctx context.Context, scope, target string) error {
+	return c.client.Del(ctx, loginFailKey(scope, target), loginLockKey(scope, target)).Err()
+}
@@ -0,0 +1,10 @@
+-- 发送失败之后删除刚刚设置的验证码，让用户可以立刻重新获取
+local key = KEYS[1]
+local cntKey = key..":cnt"
+-- 只删除自己设置的验证码，不能删掉别的请求设置的新验证码
+local code = ARGV[1]
+if redis.call("get", key) == code then
+    redis.call("del", key, cntKey)
+    return 1
+end
+return 0
@@ -0,0 +1,31 @@
+-- 失败记录，hash 中的 cnt 是本轮失败次数，locks 是已经锁定过几次
+-- login_fail:account:xxx@qq.com
+local key = KEYS[1]
+-- 锁定标记，存在即锁定
+-- login_lock:account:xxx@qq.com
+local lockKey = KEYS[2]
+-- 连续失败多少次之后锁定
+local threshold = tonumber(ARGV[1])
+-- 第一次锁定的时长，秒
+local baseLock = tonumber(ARGV[2])
+-- 锁定时长的上限，秒
+local maxLock = tonumber(ARGV[3])
+-- 失败记录的保留时间，秒
+local window = tonumber(ARGV[4])
+
+local cnt = redis.call("hincrby", key, "cnt", 1)
+redis.call("expire", key, window)
+if cnt < threshold then
+    -- 还没有达到阈值
+    return 0
+end
+
+-- 达到阈值，重新计数，锁定时长按照锁定次数翻倍
+local locks = redis.call("hincrby", key, "locks", 1)
+redis.call("hset", key, "cnt", 0)
+local lock = baseLock * 2 ^ (locks - 1)
+if lock > maxLock then
+    lock = maxLock
+end
+redis.call("set", lockKey, "", "ex", lock)
+return lock
@@ -5,14 +5,18 @@ local code = redis.call("get", key)
 local cntKey = key..":cnt"
 -- 转成一个数字
 local cnt = tonumber(redis.call("get", cntKey))
-if cnt <= 0 then
+if cnt == nil then
+    -- 验证码不存在或者已经过期
+    return -2
+elseif cnt <= 0 then
 --    说明，用户一直输错，有人搞你
 --    或者已经用过了，也是有人搞你
     return -1
 elseif expectedCode == code then
     -- 输入对了
     -- 用完，不能再用了
-    redis.call("set", cntKey, -1)
+    -- 保留过期时间，否则这个 key 永远不会过期
+    redis.call("set", cntKey, -1, "KEEPTTL")
     return 0
 else
     -- 用户手一抖，输错了
@@ -15,6 +15,7 @@ var ErrkeyNotExists = redis.Nil
 type UserCache interface {
 	Set(ctx context.Context, user domain.User) error
 	Get(ctx context.Context, id int64) (domain.User, error)
+	Del(ctx context.Context, id int64) error
 }
 
 type RedisUserCache struct {
@@ -55,6 +56,10 @@ func (cache *RedisUserCache) Get(ctx context.Context, id int64) (domain.User, er
 	return user, nil
 }
 
+func (cache *RedisUserCache) Del(ctx context.Context, id int64) error {
+	return cache.client.Del(ctx, cache.Key(id)).Err()
+}
+
 func (cache *RedisUserCache) Key(id int64) string {
 	// user:info:id
 	return fmt.Sprintf("user:info:%d", id)
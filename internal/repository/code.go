@@ -2,17 +2,21 @@ package repository
 
 import (
 	"context"
+	"webook/internal/domain"
 	"webook/internal/repository/cache"
 )
 
 var (
 	ErrCodeSendTooFrequently  = cache.ErrCodeSendTooFrequently
 	ErrCodeVerifyTooManyTimes = cache.ErrCodeVerifyTooManyTimes
+	ErrCodeVerifyFailed       = cache.ErrCodeVerifyFailed
 )
 
 type CodeRepository interface {
-	Store(ctx context.Context, biz, phone, code string) error
-	Verify(ctx context.Context, biz, phone, inputCode string) error
+	Store(ctx context.Context, channel, biz, target, code string, policy domain.CodePolicy) error
+	Verify(ctx context.Context, channel, biz, target, inputCode string) error
+	// Delete 撤销刚刚保存的验证码，例如发送失败的时候
+	Delete(ctx context.Context, channel, biz, target, code string) error
 }
 
 type CacheCodeRepository struct {
@@ -25,10 +29,15 @@ func NewCodeRepository(c cache.CodeCache) CodeRepository {
 	}
 }
 
-func (r *CacheCodeRepository) Store(ctx context.Context, biz, phone, code string) error {
-	return r.cache.SetCode(ctx, biz, phone, code)
+func (r *CacheCodeRepository) Store(ctx context.Context, channel, biz, target, code string,
+	policy domain.CodePolicy) error {
+	return r.cache.SetCode(ctx, channel, biz, target, code, policy)
 }
 
-func (r *CacheCodeRepository) Verify(ctx context.Context, biz, phone, inputCode string) error {
-	return r.cache.VerifyCode(ctx, biz, phone, inputCode)
+func (r *CacheCodeRepository) Verify(ctx context.Context, channel, biz, target, inputCode string) error {
+	return r.cache.VerifyCode(ctx, channel, biz, target, inputCode)
+}
+
+func (r *CacheCodeRepository) Delete(ctx context.Context, channel, biz, target, code string) error {
+	return r.cache.DeleteCode(ctx, channel, biz, target, code)
 }
@@ -0,0 +1,147 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrWaitingSMSNotFound = gorm.ErrRecordNotFound
+
+const (
+	// 等待发送，包括等待重试
+	AsyncStatusWaiting uint8 = iota
+	AsyncStatusSuccess
+	// 重试次数用完，不再发送
+	AsyncStatusFailed
+)
+
+// 异步发送的短信
+type AsyncSms struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 短信内容，JSON 格式的 AsyncSmsConfig
+	Config   string `gorm:"type:text"`
+	RetryCnt int
+	RetryMax int
+	Status   uint8 `gorm:"index:idx_status_next_time,priority:1"`
+	// 下一次可以发送的时间 ms
+	NextTime int64 `gorm:"index:idx_status_next_time,priority:2"`
+
+	// 创建时间 ms
+	Ctime int64
+	// 更新时间 ms
+	Utime int64
+}
+
+type AsyncSmsConfig struct {
+	Biz string
+	Tpl string
+	// 模板参数，可能包含验证码，发送结束之后清空
+	Args    map[string]string
+	Numbers []string
+}
+
+type AsyncSmsDAO interface {
+	Insert(ctx context.Context, s AsyncSms) error
+	// 抢占一条可以发送的记录，lease 时间内其他实例不会再抢到它
+	Preempt(ctx context.Context, lease time.Duration) (AsyncSms, error)
+	// 发送结束之后更新 config，不再保存模板参数
+	MarkSuccess(ctx context.Context, id int64, config string) error
+	// 发送失败，nextTime 之后再重试，config 中只保留还需要发送的号码
+	MarkRetry(ctx context.Context, id int64, config string, nextTime int64) error
+	MarkFailed(ctx context.Context, id int64, config string) error
+	CountByStatus(ctx context.Context) (map[uint8]int64, error)
+}
+
+type GORMAsyncSmsDAO struct {
+	db *gorm.DB
+}
+
+func NewAsyncSmsDAO(db *gorm.DB) AsyncSmsDAO {
+	return &GORMAsyncSmsDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMAsyncSmsDAO) Insert(ctx context.Context, s AsyncSms) error {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	s.NextTime = now
+	s.Status = AsyncStatusWaiting
+	return dao.db.WithContext(ctx).Create(&s).Error
+}
+
+func (dao *GORMAsyncSmsDAO) Preempt(ctx context.Context, lease time.Duration) (AsyncSms, error) {
+	var s AsyncSms
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		// SKIP LOCKED：多个实例同时抢占时不会互相等待
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_time <= ?", AsyncStatusWaiting, now).
+			Order("next_time").First(&s).Error
+		if err != nil {
+			return err
+		}
+
+		// 实例在发送过程中崩溃，租约到期之后其他实例会重新抢占
+		s.RetryCnt++
+		s.NextTime = now + lease.Milliseconds()
+		s.Utime = now
+		return tx.Model(&AsyncSms{}).Where("id = ?", s.Id).
+			Updates(map[string]any{
+				"retry_cnt": s.RetryCnt,
+				"next_time": s.NextTime,
+				"utime":     s.Utime,
+			}).Error
+	})
+	return s, err
+}
+
+func (dao *GORMAsyncSmsDAO) MarkSuccess(ctx context.Context, id int64, config string) error {
+	return dao.updateStatus(ctx, id, map[string]any{
+		"status": AsyncStatusSuccess,
+		"config": config,
+	})
+}
+
+func (dao *GORMAsyncSmsDAO) MarkRetry(ctx context.Context, id int64, config string, nextTime int64) error {
+	return dao.updateStatus(ctx, id, map[string]any{
+		"status":    AsyncStatusWaiting,
+		"config":    config,
+		"next_time": nextTime,
+	})
+}
+
+func (dao *GORMAsyncSmsDAO) MarkFailed(ctx context.Context, id int64, config string) error {
+	return dao.updateStatus(ctx, id, map[string]any{
+		"status": AsyncStatusFailed,
+		"config": config,
+	})
+}
+
+func (dao *GORMAsyncSmsDAO) updateStatus(ctx context.Context, id int64, updates map[string]any) error {
+	updates["utime"] = time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Model(&AsyncSms{}).Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (dao *GORMAsyncSmsDAO) CountByStatus(ctx context.Context) (map[uint8]int64, error) {
+	var rows []struct {
+		Status uint8
+		Cnt    int64
+	}
+	err := dao.db.WithContext(ctx).Model(&AsyncSms{}).
+		Select("status, COUNT(*) AS cnt").Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[uint8]int64, len(rows))
+	for _, row := range rows {
+		res[row.Status] = row.Cnt
+	}
+	return res, nil
+}
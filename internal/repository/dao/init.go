@@ -5,5 +5,5 @@ import (
 )
 
 func InitTables(db *gorm.DB) error {
-	return db.AutoMigrate(&User{})
+	return db.AutoMigrate(&User{}, &AsyncSms{}, &SmsLog{}, &UserTotp{}, &TotpRecoveryCode{})
 }
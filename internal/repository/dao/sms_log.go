@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 短信发送记录，用于排查用户收不到短信之类的问题
+type SmsLog struct {
+	Id  int64  `gorm:"primaryKey,autoIncrement"`
+	Biz string `gorm:"type:varchar(64)"`
+	// 脱敏之后的手机号
+	Phone string `gorm:"type:varchar(32)"`
+	// 手机号的 SHA-256，按照手机号查询的时候使用
+	PhoneHash string `gorm:"type:char(64);index:idx_phone_hash_ctime,priority:1"`
+	Tpl       string `gorm:"type:varchar(128)"`
+	Provider  string `gorm:"type:varchar(32)"`
+	Status    uint8
+	Message   string `gorm:"type:varchar(1024)"`
+	// 耗时 ms
+	Latency int64
+
+	// 创建时间 ms
+	Ctime int64 `gorm:"index:idx_phone_hash_ctime,priority:2;index:idx_ctime"`
+}
+
+// 零值的字段不参与过滤
+type SmsLogQuery struct {
+	PhoneHash string
+	// [Start, End) ms
+	Start  int64
+	End    int64
+	Offset int
+	Limit  int
+}
+
+type SmsLogDAO interface {
+	Insert(ctx context.Context, logs []SmsLog) error
+	// 按照创建时间倒序
+	Find(ctx context.Context, q SmsLogQuery) ([]SmsLog, error)
+}
+
+type GORMSmsLogDAO struct {
+	db *gorm.DB
+}
+
+func NewSmsLogDAO(db *gorm.DB) SmsLogDAO {
+	return &GORMSmsLogDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMSmsLogDAO) Insert(ctx context.Context, logs []SmsLog) error {
+	now := time.Now().UnixMilli()
+	for i := range logs {
+		logs[i].Ctime = now
+	}
+	return dao.db.WithContext(ctx).Create(&logs).Error
+}
+
+func (dao *GORMSmsLogDAO) Find(ctx context.Context, q SmsLogQuery) ([]SmsLog, error) {
+	db := dao.db.WithContext(ctx)
+	if q.PhoneHash != "" {
+		db = db.Where("phone_hash = ?", q.PhoneHash)
+	}
+
+	if q.Start > 0 {
+		db = db.Where("ctime >= ?", q.Start)
+	}
+
+	if q.End > 0 {
+		db = db.Where("ctime < ?", q.End)
+	}
+
+	var logs []SmsLog
+	err := db.Order("ctime DESC, id DESC").Offset(q.Offset).Limit(q.Limit).Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,166 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTotpNotFound         = gorm.ErrRecordNotFound
+	ErrTotpEnabled          = errors.New("已经启用两步验证")
+	ErrTotpStepUsed         = errors.New("两步验证码已经使用过")
+	ErrRecoveryCodeNotFound = errors.New("恢复码不存在或者已经使用过")
+)
+
+// 用户的 TOTP 密钥，生成之后需要校验一次验证码才会启用
+type UserTotp struct {
+	Id     int64  `gorm:"primaryKey,autoIncrement"`
+	Uid    int64  `gorm:"uniqueIndex"`
+	Secret string `gorm:"type:varchar(64)"`
+	// 校验过验证码之后才会启用
+	Enabled bool
+	// 最近一次使用的时间步长，同一个验证码只能使用一次
+	LastStep int64
+
+	// 创建时间 ms
+	Ctime int64
+	// 更新时间 ms
+	Utime int64
+}
+
+// 丢失验证器时使用的恢复码，只保存哈希
+type TotpRecoveryCode struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Uid      int64  `gorm:"index:idx_uid_code_hash,priority:1"`
+	CodeHash string `gorm:"type:char(64);index:idx_uid_code_hash,priority:2"`
+	Used     bool
+
+	// 创建时间 ms
+	Ctime int64
+	// 更新时间 ms
+	Utime int64
+}
+
+type TotpDAO interface {
+	// SavePending 保存还没有启用的密钥，已经启用的返回 ErrTotpEnabled
+	SavePending(ctx context.Context, uid int64, secret string) error
+	FindByUid(ctx context.Context, uid int64) (UserTotp, error)
+	// Enable 启用两步验证，同时替换掉之前的恢复码
+	Enable(ctx context.Context, uid int64, step int64, codes []TotpRecoveryCode) error
+	// UseStep 记录使用过的时间步长，不大于上一次的返回 ErrTotpStepUsed
+	UseStep(ctx context.Context, uid int64, step int64) error
+	UseRecoveryCode(ctx context.Context, uid int64, codeHash string) error
+}
+
+type GORMTotpDAO struct {
+	db *gorm.DB
+}
+
+func NewTotpDAO(db *gorm.DB) TotpDAO {
+	return &GORMTotpDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMTotpDAO) SavePending(ctx context.Context, uid int64, secret string) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t UserTotp
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uid = ?", uid).First(&t).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			return tx.Create(&UserTotp{
+				Uid:    uid,
+				Secret: secret,
+				Ctime:  now,
+				Utime:  now,
+			}).Error
+		case err != nil:
+			return err
+		case t.Enabled:
+			return ErrTotpEnabled
+		}
+
+		return tx.Model(&UserTotp{}).Where("id = ?", t.Id).Updates(map[string]any{
+			"secret": secret,
+			"utime":  now,
+		}).Error
+	})
+}
+
+func (dao *GORMTotpDAO) FindByUid(ctx context.Context, uid int64) (UserTotp, error) {
+	var t UserTotp
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&t).Error
+	return t, err
+}
+
+func (dao *GORMTotpDAO) Enable(ctx context.Context, uid int64, step int64, codes []TotpRecoveryCode) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserTotp{}).Where("uid = ? AND enabled = ?", uid, false).
+			Updates(map[string]any{
+				"enabled":   true,
+				"last_step": step,
+				"utime":     now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		// 并发启用的时候只有一个能成功
+		if res.RowsAffected == 0 {
+			return ErrTotpEnabled
+		}
+
+		if err := tx.Where("uid = ?", uid).Delete(&TotpRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for i := range codes {
+			codes[i].Uid = uid
+			codes[i].Ctime = now
+			codes[i].Utime = now
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (dao *GORMTotpDAO) UseStep(ctx context.Context, uid int64, step int64) error {
+	res := dao.db.WithContext(ctx).Model(&UserTotp{}).
+		Where("uid = ? AND enabled = ? AND last_step < ?", uid, true, step).
+		Updates(map[string]any{
+			"last_step": step,
+			"utime":     time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrTotpStepUsed
+	}
+
+	return nil
+}
+
+func (dao *GORMTotpDAO) UseRecoveryCode(ctx context.Context, uid int64, codeHash string) error {
+	res := dao.db.WithContext(ctx).Model(&TotpRecoveryCode{}).
+		Where("uid = ? AND code_hash = ? AND used = ?", uid, codeHash, false).
+		Updates(map[string]any{
+			"used":  true,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
@@ -22,6 +22,17 @@ type User struct {
 	Password string
 	Phone    sql.NullString `gorm:"unique"`
 
+	// 微信登录
+	WechatOpenId  sql.NullString `gorm:"type:varchar(128);unique"`
+	WechatUnionId sql.NullString `gorm:"type:varchar(128)"`
+
+	// 昵称
+	Nickname string `gorm:"type:varchar(128)"`
+	// 生日 ms
+	Birthday int64
+	// 个人简介
+	AboutMe string `gorm:"type:varchar(4096)"`
+
 	// 创建时间 ms
 	Ctime int64
 	// 更新时间 ms
@@ -32,7 +43,12 @@ type UserDAO interface {
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FindById(ctx context.Context, id int64) (User, error)
 	FindByPhone(ctx context.Context, phone string) (User, error)
+	FindByWechat(ctx context.Context, openId string) (User, error)
 	Insert(ctx context.Context, u User) error
+	UpdateById(ctx context.Context, u User) error
+	UpdatePasswordById(ctx context.Context, id int64, password string) error
+	UpdatePhoneById(ctx context.Context, id int64, phone string) error
+	UpdateEmailById(ctx context.Context, id int64, email string) error
 }
 
 type GORMUserDAO struct {
@@ -80,3 +96,64 @@ func (dao *GORMUserDAO) FindByPhone(ctx context.Context, phone string) (User, er
 	err := dao.db.WithContext(ctx).Where("phone = ?", phone).First(&u).Error
 	return u, err
 }
+
+func (dao *GORMUserDAO) FindByWechat(ctx context.Context, openId string) (User, error) {
+	var u User
+	err := dao.db.WithContext(ctx).Where("wechat_open_id = ?", openId).First(&u).Error
+	return u, err
+}
+
+// 更新用户的非敏感信息
+func (dao *GORMUserDAO) UpdateById(ctx context.Context, u User) error {
+	res := dao.db.WithContext(ctx).Model(&User{}).Where("id = ?", u.Id).
+		Updates(map[string]any{
+			"nickname": u.Nickname,
+			"birthday": u.Birthday,
+			"about_me": u.AboutMe,
+			"utime":    time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// 更新用户密码，password 是加密之后的密码
+func (dao *GORMUserDAO) UpdatePasswordById(ctx context.Context, id int64, password string) error {
+	return dao.updateById(ctx, id, map[string]any{"password": password})
+}
+
+// 绑定或者修改手机号，手机号已经被别的用户使用时返回 ErrUserDuplicate
+func (dao *GORMUserDAO) UpdatePhoneById(ctx context.Context, id int64, phone string) error {
+	return dao.updateById(ctx, id, map[string]any{"phone": phone})
+}
+
+// 修改邮箱，邮箱已经被别的用户使用时返回 ErrUserDuplicate
+func (dao *GORMUserDAO) UpdateEmailById(ctx context.Context, id int64, email string) error {
+	return dao.updateById(ctx, id, map[string]any{"email": email})
+}
+
+func (dao *GORMUserDAO) updateById(ctx context.Context, id int64, fields map[string]any) error {
+	fields["utime"] = time.Now().UnixMilli()
+	res := dao.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).Updates(fields)
+	if mysqlErr, ok := res.Error.(*mysql.MySQLError); ok {
+		const duplicateErr uint16 = 1062
+		if mysqlErr.Number == duplicateErr {
+			return ErrUserDuplicate
+		}
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+)
+
+type LoginLockRepository interface {
+	LockTTL(ctx context.Context, scope, target string) (time.Duration, error)
+	Fail(ctx context.Context, scope, target string, policy domain.LoginLockPolicy) (time.Duration, error)
+	Reset(ctx context.Context, scope, target string) error
+}
+
+type CacheLoginLockRepository struct {
+	cache cache.LoginLockCache
+}
+
+func NewLoginLockRepository(c cache.LoginLockCache) LoginLockRepository {
+	return &CacheLoginLockRepository{
+		cache: c,
+	}
+}
+
+func (r *CacheLoginLockRepository) LockTTL(ctx context.Context, scope, target string) (time.Duration, error) {
+	return r.cache.LockTTL(ctx, scope, target)
+}
+
+func (r *CacheLoginLockRepository) Fail(ctx context.Context, scope, target string,
+	policy domain.LoginLockPolicy) (time.Duration, error) {
+	return r.cache.Fail(ctx, scope, target, policy)
+}
+
+func (r *CacheLoginLockRepository) Reset(ctx context.Context, scope, target string) error {
+	return r.cache.Reset(ctx, scope, target)
+}
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/async_sms.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/async_sms.go -package=repomocks -destination=./internal/repository/mock/async_sms.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAsyncSmsRepository is a mock of AsyncSmsRepository interface.
+type MockAsyncSmsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAsyncSmsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAsyncSmsRepositoryMockRecorder is the mock recorder for MockAsyncSmsRepository.
+type MockAsyncSmsRepositoryMockRecorder struct {
+	mock *MockAsyncSmsRepository
+}
+
+// NewMockAsyncSmsRepository creates a new mock instance.
+func NewMockAsyncSmsRepository(ctrl *gomock.Controller) *MockAsyncSmsRepository {
+	mock := &MockAsyncSmsRepository{ctrl: ctrl}
+	mock.recorder = &MockAsyncSmsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAsyncSmsRepository) EXPECT() *MockAsyncSmsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockAsyncSmsRepository) Add(ctx context.Context, s domain.AsyncSms) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockAsyncSmsRepositoryMockRecorder) Add(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockAsyncSmsRepository)(nil).Add), ctx, s)
+}
+
+// PreemptWaitingSMS mocks base method.
+func (m *MockAsyncSmsRepository) PreemptWaitingSMS(ctx context.Context, lease time.Duration) (domain.AsyncSms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreemptWaitingSMS", ctx, lease)
+	ret0, _ := ret[0].(domain.AsyncSms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreemptWaitingSMS indicates an expected call of PreemptWaitingSMS.
+func (mr *MockAsyncSmsRepositoryMockRecorder) PreemptWaitingSMS(ctx, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreemptWaitingSMS", reflect.TypeOf((*MockAsyncSmsRepository)(nil).PreemptWaitingSMS), ctx, lease)
+}
+
+// ReportFailed mocks base method.
+func (m *MockAsyncSmsRepository) ReportFailed(ctx context.Context, s domain.AsyncSms) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportFailed", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportFailed indicates an expected call of ReportFailed.
+func (mr *MockAsyncSmsRepositoryMockRecorder) ReportFailed(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportFailed", reflect.TypeOf((*MockAsyncSmsRepository)(nil).ReportFailed), ctx, s)
+}
+
+// ReportRetry mocks base method.
+func (m *MockAsyncSmsRepository) ReportRetry(ctx context.Context, s domain.AsyncSms, nextTime time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportRetry", ctx, s, nextTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportRetry indicates an expected call of ReportRetry.
+func (mr *MockAsyncSmsRepositoryMockRecorder) ReportRetry(ctx, s, nextTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportRetry", reflect.TypeOf((*MockAsyncSmsRepository)(nil).ReportRetry), ctx, s, nextTime)
+}
+
+// ReportSuccess mocks base method.
+func (m *MockAsyncSmsRepository) ReportSuccess(ctx context.Context, s domain.AsyncSms) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportSuccess", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReportSuccess indicates an expected call of ReportSuccess.
+func (mr *MockAsyncSmsRepositoryMockRecorder) ReportSuccess(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportSuccess", reflect.TypeOf((*MockAsyncSmsRepository)(nil).ReportSuccess), ctx, s)
+}
+
+// Stats mocks base method.
+func (m *MockAsyncSmsRepository) Stats(ctx context.Context) (domain.AsyncSmsStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx)
+	ret0, _ := ret[0].(domain.AsyncSmsStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockAsyncSmsRepositoryMockRecorder) Stats(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockAsyncSmsRepository)(nil).Stats), ctx)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/code.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/code.go -package=repomocks -destination=./internal/repository/mock/code.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCodeRepository is a mock of CodeRepository interface.
+type MockCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCodeRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCodeRepositoryMockRecorder is the mock recorder for MockCodeRepository.
+type MockCodeRepositoryMockRecorder struct {
+	mock *MockCodeRepository
+}
+
+// NewMockCodeRepository creates a new mock instance.
+func NewMockCodeRepository(ctrl *gomock.Controller) *MockCodeRepository {
+	mock := &MockCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCodeRepository) EXPECT() *MockCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCodeRepository) Delete(ctx context.Context, channel, biz, target, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, channel, biz, target, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCodeRepositoryMockRecorder) Delete(ctx, channel, biz, target, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCodeRepository)(nil).Delete), ctx, channel, biz, target, code)
+}
+
+// Store mocks base method.
+func (m *MockCodeRepository) Store(ctx context.Context, channel, biz, target, code string, policy domain.CodePolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, channel, biz, target, code, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockCodeRepositoryMockRecorder) Store(ctx, channel, biz, target, code, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCodeRepository)(nil).Store), ctx, channel, biz, target, code, policy)
+}
+
+// Verify mocks base method.
+func (m *MockCodeRepository) Verify(ctx context.Context, channel, biz, target, inputCode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, channel, biz, target, inputCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockCodeRepositoryMockRecorder) Verify(ctx, channel, biz, target, inputCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeRepository)(nil).Verify), ctx, channel, biz, target, inputCode)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/login_lock.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/login_lock.go -package=repomocks -destination=./internal/repository/mock/login_lock.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginLockRepository is a mock of LoginLockRepository interface.
+type MockLoginLockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginLockRepositoryMockRecorder is the mock recorder for MockLoginLockRepository.
+type MockLoginLockRepositoryMockRecorder struct {
+	mock *MockLoginLockRepository
+}
+
+// NewMockLoginLockRepository creates a new mock instance.
+func NewMockLoginLockRepository(ctrl *gomock.Controller) *MockLoginLockRepository {
+	mock := &MockLoginLockRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginLockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLockRepository) EXPECT() *MockLoginLockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Fail mocks base method.
+func (m *MockLoginLockRepository) Fail(ctx context.Context, scope, target string, policy domain.LoginLockPolicy) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, scope, target, policy)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockLoginLockRepositoryMockRecorder) Fail(ctx, scope, target, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockLoginLockRepository)(nil).Fail), ctx, scope, target, policy)
+}
+
+// LockTTL mocks base method.
+func (m *MockLoginLockRepository) LockTTL(ctx context.Context, scope, target string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockTTL", ctx, scope, target)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockTTL indicates an expected call of LockTTL.
+func (mr *MockLoginLockRepositoryMockRecorder) LockTTL(ctx, scope, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTTL", reflect.TypeOf((*MockLoginLockRepository)(nil).LockTTL), ctx, scope, target)
+}
+
+// Reset mocks base method.
+func (m *MockLoginLockRepository) Reset(ctx context.Context, scope, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, scope, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginLockRepositoryMockRecorder) Reset(ctx, scope, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginLockRepository)(nil).Reset), ctx, scope, target)
+}
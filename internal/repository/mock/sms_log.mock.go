@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/sms_log.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/sms_log.go -package=repomocks -destination=./internal/repository/mock/sms_log.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSmsLogRepository is a mock of SmsLogRepository interface.
+type MockSmsLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmsLogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSmsLogRepositoryMockRecorder is the mock recorder for MockSmsLogRepository.
+type MockSmsLogRepositoryMockRecorder struct {
+	mock *MockSmsLogRepository
+}
+
+// NewMockSmsLogRepository creates a new mock instance.
+func NewMockSmsLogRepository(ctrl *gomock.Controller) *MockSmsLogRepository {
+	mock := &MockSmsLogRepository{ctrl: ctrl}
+	mock.recorder = &MockSmsLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSmsLogRepository) EXPECT() *MockSmsLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockSmsLogRepository) Add(ctx context.Context, logs ...domain.SmsLog) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range logs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Add", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockSmsLogRepositoryMockRecorder) Add(ctx any, logs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, logs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockSmsLogRepository)(nil).Add), varargs...)
+}
+
+// Find mocks base method.
+func (m *MockSmsLogRepository) Find(ctx context.Context, q domain.SmsLogQuery) ([]domain.SmsLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, q)
+	ret0, _ := ret[0].([]domain.SmsLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockSmsLogRepositoryMockRecorder) Find(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockSmsLogRepository)(nil).Find), ctx, q)
+}
@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/totp.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/totp.go -package=repomocks -destination=./internal/repository/mock/totp.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTotpRepository is a mock of TotpRepository interface.
+type MockTotpRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTotpRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTotpRepositoryMockRecorder is the mock recorder for MockTotpRepository.
+type MockTotpRepositoryMockRecorder struct {
+	mock *MockTotpRepository
+}
+
+// NewMockTotpRepository creates a new mock instance.
+func NewMockTotpRepository(ctrl *gomock.Controller) *MockTotpRepository {
+	mock := &MockTotpRepository{ctrl: ctrl}
+	mock.recorder = &MockTotpRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTotpRepository) EXPECT() *MockTotpRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Enable mocks base method.
+func (m *MockTotpRepository) Enable(ctx context.Context, uid, step int64, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, step, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTotpRepositoryMockRecorder) Enable(ctx, uid, step, recoveryCodes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTotpRepository)(nil).Enable), ctx, uid, step, recoveryCodes)
+}
+
+// FindByUid mocks base method.
+func (m *MockTotpRepository) FindByUid(ctx context.Context, uid int64) (domain.Totp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].(domain.Totp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockTotpRepositoryMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockTotpRepository)(nil).FindByUid), ctx, uid)
+}
+
+// SavePending mocks base method.
+func (m *MockTotpRepository) SavePending(ctx context.Context, uid int64, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePending", ctx, uid, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePending indicates an expected call of SavePending.
+func (mr *MockTotpRepositoryMockRecorder) SavePending(ctx, uid, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePending", reflect.TypeOf((*MockTotpRepository)(nil).SavePending), ctx, uid, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTotpRepository) UseRecoveryCode(ctx context.Context, uid int64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, uid, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTotpRepositoryMockRecorder) UseRecoveryCode(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTotpRepository)(nil).UseRecoveryCode), ctx, uid, code)
+}
+
+// UseStep mocks base method.
+func (m *MockTotpRepository) UseStep(ctx context.Context, uid, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseStep", ctx, uid, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseStep indicates an expected call of UseStep.
+func (mr *MockTotpRepositoryMockRecorder) UseStep(ctx, uid, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseStep", reflect.TypeOf((*MockTotpRepository)(nil).UseStep), ctx, uid, step)
+}
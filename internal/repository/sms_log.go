@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+	"webook/pkg/mask"
+)
+
+// 服务商返回的信息最多保存的字符数，与表结构保持一致
+const smsLogMessageMaxLen = 1024
+
+type SmsLogRepository interface {
+	Add(ctx context.Context, logs ...domain.SmsLog) error
+	Find(ctx context.Context, q domain.SmsLogQuery) ([]domain.SmsLog, error)
+}
+
+// 数据库中只保存脱敏之后的手机号，查询使用手机号的哈希
+type DBSmsLogRepository struct {
+	dao dao.SmsLogDAO
+	// 手机号只有 11 位，不加密钥的哈希可以直接穷举出原文
+	hashKey []byte
+}
+
+func NewSmsLogRepository(dao dao.SmsLogDAO, hashKey []byte) SmsLogRepository {
+	return &DBSmsLogRepository{
+		dao:     dao,
+		hashKey: hashKey,
+	}
+}
+
+func (r *DBSmsLogRepository) Add(ctx context.Context, logs ...domain.SmsLog) error {
+	entities := make([]dao.SmsLog, 0, len(logs))
+	for _, l := range logs {
+		entities = append(entities, dao.SmsLog{
+			Biz:       l.Biz,
+			Phone:     mask.Phone(l.Phone),
+			PhoneHash: r.hashPhone(l.Phone),
+			Tpl:       l.Tpl,
+			Provider:  l.Provider,
+			Status:    uint8(l.Status),
+			Message:   truncate(l.Message, smsLogMessageMaxLen),
+			Latency:   l.Latency.Milliseconds(),
+		})
+	}
+	return r.dao.Insert(ctx, entities)
+}
+
+func (r *DBSmsLogRepository) Find(ctx context.Context, q domain.SmsLogQuery) ([]domain.SmsLog, error) {
+	dq := dao.SmsLogQuery{
+		Offset: q.Offset,
+		Limit:  q.Limit,
+	}
+	if q.Phone != "" {
+		dq.PhoneHash = r.hashPhone(q.Phone)
+	}
+	if !q.Start.IsZero() {
+		dq.Start = q.Start.UnixMilli()
+	}
+	if !q.End.IsZero() {
+		dq.End = q.End.UnixMilli()
+	}
+
+	entities, err := r.dao.Find(ctx, dq)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]domain.SmsLog, 0, len(entities))
+	for _, e := range entities {
+		logs = append(logs, domain.SmsLog{
+			Id:       e.Id,
+			Biz:      e.Biz,
+			Phone:    e.Phone,
+			Tpl:      e.Tpl,
+			Provider: e.Provider,
+			Status:   domain.SmsStatus(e.Status),
+			Message:  e.Message,
+			Latency:  time.Duration(e.Latency) * time.Millisecond,
+			Ctime:    time.UnixMilli(e.Ctime),
+		})
+	}
+	return logs, nil
+}
+
+func (r *DBSmsLogRepository) hashPhone(phone string) string {
+	h := hmac.New(sha256.New, r.hashKey)
+	h.Write([]byte(phone))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 按照字符截断，避免截断半个汉字
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+)
+
+var (
+	ErrTotpNotFound         = dao.ErrTotpNotFound
+	ErrTotpEnabled          = dao.ErrTotpEnabled
+	ErrTotpStepUsed         = dao.ErrTotpStepUsed
+	ErrRecoveryCodeNotFound = dao.ErrRecoveryCodeNotFound
+)
+
+type TotpRepository interface {
+	SavePending(ctx context.Context, uid int64, secret string) error
+	FindByUid(ctx context.Context, uid int64) (domain.Totp, error)
+	// Enable 启用两步验证，恢复码只保存哈希
+	Enable(ctx context.Context, uid int64, step int64, recoveryCodes []string) error
+	UseStep(ctx context.Context, uid int64, step int64) error
+	UseRecoveryCode(ctx context.Context, uid int64, code string) error
+}
+
+type DBTotpRepository struct {
+	dao dao.TotpDAO
+}
+
+func NewTotpRepository(dao dao.TotpDAO) TotpRepository {
+	return &DBTotpRepository{
+		dao: dao,
+	}
+}
+
+func (r *DBTotpRepository) SavePending(ctx context.Context, uid int64, secret string) error {
+	return r.dao.SavePending(ctx, uid, secret)
+}
+
+func (r *DBTotpRepository) FindByUid(ctx context.Context, uid int64) (domain.Totp, error) {
+	t, err := r.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return domain.Totp{}, err
+	}
+
+	return domain.Totp{
+		Uid:      t.Uid,
+		Secret:   t.Secret,
+		Enabled:  t.Enabled,
+		LastStep: t.LastStep,
+	}, nil
+}
+
+func (r *DBTotpRepository) Enable(ctx context.Context, uid int64, step int64, recoveryCodes []string) error {
+	codes := make([]dao.TotpRecoveryCode, 0, len(recoveryCodes))
+	for _, c := range recoveryCodes {
+		codes = append(codes, dao.TotpRecoveryCode{CodeHash: hashRecoveryCode(c)})
+	}
+	return r.dao.Enable(ctx, uid, step, codes)
+}
+
+func (r *DBTotpRepository) UseStep(ctx context.Context, uid int64, step int64) error {
+	return r.dao.UseStep(ctx, uid, step)
+}
+
+func (r *DBTotpRepository) UseRecoveryCode(ctx context.Context, uid int64, code string) error {
+	return r.dao.UseRecoveryCode(ctx, uid, hashRecoveryCode(code))
+}
+
+// 恢复码是足够长的随机串，不需要加盐的慢哈希
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"log"
 	"time"
 	"webook/internal/domain"
 	"webook/internal/repository/cache"
@@ -19,6 +20,11 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (domain.User, error)
 	FindById(ctx context.Context, id int64) (domain.User, error)
 	FindByPhone(ctx context.Context, phone string) (domain.User, error)
+	FindByWechat(ctx context.Context, openId string) (domain.User, error)
+	Update(ctx context.Context, u domain.User) error
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	UpdatePhone(ctx context.Context, id int64, phone string) error
+	UpdateEmail(ctx context.Context, id int64, email string) error
 }
 
 // 存储层
@@ -70,8 +76,10 @@ func (r *CachedUserRepository) FindById(ctx context.Context, id int64) (domain.U
 	err = r.cache.Set(ctx, u)
 	if err != nil {
 		// 缓存写入失败，打日志，做监控
+		// 数据已经从数据库中查到了，不影响本次返回
+		log.Println("用户缓存写回失败......", err)
 	}
-	return u, err
+	return u, nil
 
 	// 存在别的错误（可能redis崩溃，需要保护数据库）
 }
@@ -85,22 +93,93 @@ func (r *CachedUserRepository) FindByPhone(ctx context.Context, phone string) (d
 	return r.entityToDomain(u), nil
 }
 
+func (r *CachedUserRepository) FindByWechat(ctx context.Context, openId string) (domain.User, error) {
+	u, err := r.dao.FindByWechat(ctx, openId)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return r.entityToDomain(u), nil
+}
+
+// 更新用户非敏感信息，并删除缓存，保证 FindById 不会读到旧数据
+func (r *CachedUserRepository) Update(ctx context.Context, u domain.User) error {
+	err := r.dao.UpdateById(ctx, r.domainToEntify(u))
+	if err != nil {
+		return err
+	}
+
+	return r.cache.Del(ctx, u.Id)
+}
+
+// 更新用户密码，缓存中的用户数据包含密码，同样需要删除
+func (r *CachedUserRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+	err := r.dao.UpdatePasswordById(ctx, id, password)
+	if err != nil {
+		return err
+	}
+
+	return r.cache.Del(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	err := r.dao.UpdatePhoneById(ctx, id, phone)
+	if err != nil {
+		return err
+	}
+
+	return r.cache.Del(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdateEmail(ctx context.Context, id int64, email string) error {
+	err := r.dao.UpdateEmailById(ctx, id, email)
+	if err != nil {
+		return err
+	}
+
+	return r.cache.Del(ctx, id)
+}
+
 func (r *CachedUserRepository) domainToEntify(u domain.User) dao.User {
+	var birthday int64
+	if !u.Birthday.IsZero() {
+		birthday = u.Birthday.UnixMilli()
+	}
+
 	return dao.User{
-		Id:       u.Id,
-		Email:    sql.NullString{String: u.Email, Valid: u.Email != ""},
-		Phone:    sql.NullString{String: u.Phone, Valid: u.Phone != ""},
-		Password: u.Password,
-		Ctime:    u.Ctime.UnixMilli(),
+		Id:            u.Id,
+		Email:         sql.NullString{String: u.Email, Valid: u.Email != ""},
+		Phone:         sql.NullString{String: u.Phone, Valid: u.Phone != ""},
+		WechatOpenId:  sql.NullString{String: u.WechatInfo.OpenId, Valid: u.WechatInfo.OpenId != ""},
+		WechatUnionId: sql.NullString{String: u.WechatInfo.UnionId, Valid: u.WechatInfo.UnionId != ""},
+		Password:      u.Password,
+		Nickname:      u.Nickname,
+		Birthday:      birthday,
+		AboutMe:       u.AboutMe,
+		Ctime:         u.Ctime.UnixMilli(),
 	}
 }
 
 func (r *CachedUserRepository) entityToDomain(u dao.User) domain.User {
+	var birthday time.Time
+	if u.Birthday != 0 {
+		// 生日按照 UTC 的零点保存，转回 UTC 才是用户填写的那一天，
+		// 否则在 UTC 以西的服务器上会变成前一天
+		birthday = time.UnixMilli(u.Birthday).UTC()
+	}
+
 	return domain.User{
-		Id:       u.Id,
-		Email:    u.Email.String,
-		Phone:    u.Phone.String,
+		Id:    u.Id,
+		Email: u.Email.String,
+		Phone: u.Phone.String,
+		WechatInfo: domain.WechatInfo{
+			OpenId:  u.WechatOpenId.String,
+			UnionId: u.WechatUnionId.String,
+		},
 		Password: u.Password,
+		Nickname: u.Nickname,
+		Birthday: birthday,
+		AboutMe:  u.AboutMe,
 		Ctime:    time.UnixMilli(u.Ctime),
 	}
 }
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"testing"
+	"time"
+	"webook/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 编辑资料时保存的生日，在查看资料时必须是同一天，与服务器的时区无关
+func TestCachedUserRepository_Birthday(t *testing.T) {
+	local := time.Local
+	t.Cleanup(func() {
+		time.Local = local
+	})
+
+	for _, loc := range []*time.Location{
+		time.UTC,
+		time.FixedZone("UTC-8", -8*60*60),
+		time.FixedZone("UTC+8", 8*60*60),
+	} {
+		t.Run(loc.String(), func(t *testing.T) {
+			time.Local = loc
+
+			// 与 UserHandler.Edit 一样解析生日
+			birthday, err := time.Parse(time.DateOnly, "1990-05-06")
+			require.NoError(t, err)
+
+			r := &CachedUserRepository{}
+			u := r.entityToDomain(r.domainToEntify(domain.User{Id: 1, Birthday: birthday}))
+			// 与 UserHandler.ProfileJWT 一样格式化生日
+			assert.Equal(t, "1990-05-06", u.Birthday.Format(time.DateOnly))
+		})
+	}
+}
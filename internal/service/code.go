@@ -2,55 +2,146 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
-	"math/rand"
+	"log"
+	"math/big"
+	"time"
+	"webook/internal/domain"
 	"webook/internal/repository"
+	"webook/internal/service/email"
 	"webook/internal/service/sms"
+	"webook/internal/service/sms/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
 
-const codeTplId = "1877556"
+const (
+	// 验证码短信模板的逻辑名称，各个服务商的模板 id 在配置文件中
+	codeTplName = "login_code"
+	// 发送失败之后撤销验证码的超时时间
+	codeDeleteTimeout = time.Second
+)
 
 var (
 	ErrCodeSendTooFrequently  = repository.ErrCodeSendTooFrequently
 	ErrCodeVerifyTooManyTimes = repository.ErrCodeVerifyTooManyTimes
+	ErrCodeVerifyFailed       = repository.ErrCodeVerifyFailed
+	ErrCodeSendLimited        = ratelimit.ErrLimited
+	ErrUnknownCodeBiz         = errors.New("未配置验证码策略的业务")
 )
 
+// CodePolicies 业务 → 验证码策略
+type CodePolicies map[string]domain.CodePolicy
+
+// CodeService target 是验证码的接收方，短信验证码是手机号，邮件验证码是邮箱
 type CodeService interface {
-	Send(ctx context.Context, biz string, phone string) error
-	Verify(c *gin.Context, biz string, phone string, inputCode string) error
+	Send(ctx context.Context, biz string, target string) error
+	Verify(c *gin.Context, biz string, target string, inputCode string) error
+}
+
+// EmailCodeService 通过邮件发送的验证码，与短信验证码分开保存
+type EmailCodeService interface {
+	CodeService
+}
+
+// CodeChannel 验证码的发送渠道
+type CodeChannel interface {
+	// Name 渠道名，不同渠道的验证码分开保存，例如 phone、email
+	Name() string
+	Send(ctx context.Context, biz, target, code string, policy domain.CodePolicy) error
 }
 
 type codeService struct {
-	r      repository.CodeRepository
-	smsSvc sms.Service
+	r        repository.CodeRepository
+	channel  CodeChannel
+	policies CodePolicies
+}
+
+// NewCodeService 通过短信发送验证码
+func NewCodeService(r repository.CodeRepository, smsSvc sms.Service, policies CodePolicies) CodeService {
+	return &codeService{r: r, channel: &smsCodeChannel{svc: smsSvc}, policies: policies}
 }
 
-func NewCodeService(r repository.CodeRepository, smsSvc sms.Service) CodeService {
-	return &codeService{r: r, smsSvc: smsSvc}
+func NewEmailCodeService(r repository.CodeRepository, emailSvc email.Service, policies CodePolicies) EmailCodeService {
+	return &codeService{r: r, channel: &emailCodeChannel{svc: emailSvc}, policies: policies}
 }
 
 // 发送验证码 biz:业务类型 code:验证码
-func (svc *codeService) Send(ctx context.Context, biz string, phone string) error {
-	code := svc.generateCode()
-	err := svc.r.Store(ctx, biz, phone, code)
+func (svc *codeService) Send(ctx context.Context, biz string, target string) error {
+	policy, ok := svc.policies[biz]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCodeBiz, biz)
+	}
+
+	code, err := svc.generateCode(policy)
 	if err != nil {
 		return err
 	}
 
-	err = svc.smsSvc.Send(ctx, codeTplId, []string{code}, phone)
+	// 先保存再发送，保存时检查重新发送的间隔，避免并发请求发出多条验证码
+	err = svc.r.Store(ctx, svc.channel.Name(), biz, target, code, policy)
+	if err != nil {
+		return err
+	}
+
+	err = svc.channel.Send(ctx, biz, target, code, policy)
+	if err != nil {
+		// 用户没有收到验证码，撤销之后可以立刻重新获取，不需要等待重新发送的间隔
+		dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), codeDeleteTimeout)
+		defer cancel()
+		if er := svc.r.Delete(dctx, svc.channel.Name(), biz, target, code); er != nil {
+			log.Println("撤销发送失败的验证码失败......", er)
+		}
+	}
 	return err
 }
 
 // 校验验证码
-func (svc *codeService) Verify(c *gin.Context, biz string, phone string,
+func (svc *codeService) Verify(c *gin.Context, biz string, target string,
 	inputCode string) error {
-	return svc.r.Verify(c, biz, phone, inputCode)
+	return svc.r.Verify(c, svc.channel.Name(), biz, target, inputCode)
+}
+
+// 使用 crypto/rand 按照策略生成验证码，避免验证码被预测
+func (svc *codeService) generateCode(policy domain.CodePolicy) (string, error) {
+	alphabet := []rune(policy.Alphabet)
+	size := big.NewInt(int64(len(alphabet)))
+	code := make([]rune, 0, policy.Length)
+	for i := 0; i < policy.Length; i++ {
+		idx, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		code = append(code, alphabet[idx.Int64()])
+	}
+	return string(code), nil
+}
+
+type smsCodeChannel struct {
+	svc sms.Service
+}
+
+func (c *smsCodeChannel) Name() string {
+	return "phone"
+}
+
+func (c *smsCodeChannel) Send(ctx context.Context, biz, phone, code string, policy domain.CodePolicy) error {
+	ctx = sms.WithBiz(ctx, biz)
+	// 触发短信限流时返回 ErrCodeSendLimited，由调用方提示用户稍后再试
+	return c.svc.Send(ctx, codeTplName, []sms.NamedArg{{Name: "code", Val: code}}, phone)
+}
+
+type emailCodeChannel struct {
+	svc email.Service
+}
+
+func (c *emailCodeChannel) Name() string {
+	return "email"
 }
 
-// 生成验证码
-func (svc *codeService) generateCode() string {
-	num := rand.Intn(1000000)
-	return fmt.Sprintf("%06d", num)
+func (c *emailCodeChannel) Send(ctx context.Context, biz, addr, code string, policy domain.CodePolicy) error {
+	body := fmt.Sprintf("您的验证码是 %s，%d 分钟内有效，请勿泄露给他人。", code, int(policy.TTL.Minutes()))
+	return c.svc.Send(ctx, "webook 验证码", body, addr)
 }
@@ -1 +1,160 @@
 package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mock"
+	emailmocks "webook/internal/service/email/mock"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testPolicies = CodePolicies{
+	"login": {
+		Length:         6,
+		Alphabet:       "0123456789",
+		TTL:            10 * time.Minute,
+		ResendInterval: time.Minute,
+		MaxAttempts:    3,
+	},
+	"bind_phone": {
+		Length:         8,
+		Alphabet:       "ABC",
+		TTL:            5 * time.Minute,
+		ResendInterval: time.Minute,
+		MaxAttempts:    5,
+	},
+}
+
+func TestCodeService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service)
+		biz     string
+		wantErr error
+	}{
+		{
+			name: "发送成功",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				var code string
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "phone", "bind_phone", "13812345678", gomock.Any(), testPolicies["bind_phone"]).
+					DoAndReturn(func(ctx context.Context, channel, biz, phone, c string, policy domain.CodePolicy) error {
+						code = c
+						return nil
+					})
+				smsSvc := smsmocks.NewMockService(ctrl)
+				smsSvc.EXPECT().Send(gomock.Any(), codeTplName, gomock.Any(), "13812345678").
+					DoAndReturn(func(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+						assert.Equal(t, "bind_phone", sms.BizFromContext(ctx))
+						assert.Equal(t, []sms.NamedArg{{Name: "code", Val: code}}, args)
+						return nil
+					})
+				return repo, smsSvc
+			},
+			biz: "bind_phone",
+		},
+		{
+			name: "未配置策略的业务",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				return repomocks.NewMockCodeRepository(ctrl), smsmocks.NewMockService(ctrl)
+			},
+			biz:     "unknown",
+			wantErr: ErrUnknownCodeBiz,
+		},
+		{
+			name: "发送太频繁",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "phone", "login", "13812345678", gomock.Any(), testPolicies["login"]).
+					Return(ErrCodeSendTooFrequently)
+				return repo, smsmocks.NewMockService(ctrl)
+			},
+			biz:     "login",
+			wantErr: ErrCodeSendTooFrequently,
+		},
+		{
+			name: "短信发送失败，撤销验证码",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				var code string
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "phone", "login", "13812345678", gomock.Any(), testPolicies["login"]).
+					DoAndReturn(func(ctx context.Context, channel, biz, phone, c string, policy domain.CodePolicy) error {
+						code = c
+						return nil
+					})
+				// 撤销之后用户不需要等待重新发送的间隔
+				repo.EXPECT().Delete(gomock.Any(), "phone", "login", "13812345678", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, channel, biz, phone, c string) error {
+						assert.Equal(t, code, c)
+						return nil
+					})
+				smsSvc := smsmocks.NewMockService(ctrl)
+				smsSvc.EXPECT().Send(gomock.Any(), codeTplName, gomock.Any(), "13812345678").
+					Return(ErrCodeSendLimited)
+				return repo, smsSvc
+			},
+			biz:     "login",
+			wantErr: ErrCodeSendLimited,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, smsSvc := tc.mock(ctrl)
+			svc := NewCodeService(repo, smsSvc, testPolicies)
+			err := svc.Send(context.Background(), tc.biz, "13812345678")
+			assert.True(t, errors.Is(err, tc.wantErr))
+		})
+	}
+}
+
+func TestCodeService_generateCode(t *testing.T) {
+	svc := &codeService{}
+	for _, policy := range []domain.CodePolicy{testPolicies["login"], testPolicies["bind_phone"],
+		{Length: 4, Alphabet: "验证码"}} {
+		for i := 0; i < 100; i++ {
+			code, err := svc.generateCode(policy)
+			require.NoError(t, err)
+			assert.Equal(t, policy.Length, utf8.RuneCountInString(code))
+			for _, c := range code {
+				assert.Contains(t, policy.Alphabet, string(c))
+			}
+		}
+	}
+}
+
+func TestEmailCodeService_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var code string
+	repo := repomocks.NewMockCodeRepository(ctrl)
+	repo.EXPECT().Store(gomock.Any(), "email", "login", "a@qq.com", gomock.Any(), testPolicies["login"]).
+		DoAndReturn(func(ctx context.Context, channel, biz, addr, c string, policy domain.CodePolicy) error {
+			code = c
+			return nil
+		})
+	emailSvc := emailmocks.NewMockService(ctrl)
+	emailSvc.EXPECT().Send(gomock.Any(), "webook 验证码", gomock.Any(), "a@qq.com").
+		DoAndReturn(func(ctx context.Context, subject, body string, to ...string) error {
+			assert.Equal(t, "您的验证码是 "+code+"，10 分钟内有效，请勿泄露给他人。", body)
+			return nil
+		})
+
+	svc := NewEmailCodeService(repo, emailSvc, testPolicies)
+	assert.NoError(t, svc.Send(context.Background(), "login", "a@qq.com"))
+}
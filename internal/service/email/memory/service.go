@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+)
+
+// Service 只在控制台打印邮件，用于本地开发
+type Service struct {
+}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Send(ctx context.Context, subject, body string, to ...string) error {
+	fmt.Println(to, subject, body)
+	return nil
+}
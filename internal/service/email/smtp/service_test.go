@@ -0,0 +1,171 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 收到的邮件
+type received struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// 最简单的 SMTP 服务器，只处理一个连接，rejectRcpt 中的收件人会被拒绝
+func newStubServer(t *testing.T, rejectRcpt string) (string, int, <-chan received) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	ch := make(chan received, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var r received
+		defer func() {
+			ch <- r
+		}()
+		reader := bufio.NewReader(conn)
+		reply := func(s string) {
+			_, _ = io.WriteString(conn, s+"\r\n")
+		}
+
+		reply("220 localhost ESMTP stub")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				r.auth = line
+				reply("235 2.7.0 Authentication successful")
+			case "MAIL":
+				r.from = line
+				reply("250 OK")
+			case "RCPT":
+				if rejectRcpt != "" && strings.Contains(line, rejectRcpt) {
+					reply("550 No such user")
+					continue
+				}
+				r.to = append(r.to, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				r.data = data.String()
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, p, ch
+}
+
+func TestService_Send(t *testing.T) {
+	host, port, ch := newStubServer(t, "")
+	svc := NewService(host, port, "webook", "secret", "noreply@webook.com", time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := svc.Send(ctx, "webook 验证码", "您的验证码是 123456", "a@qq.com", "b@qq.com")
+	require.NoError(t, err)
+
+	r := <-ch
+	assert.Equal(t, "AUTH PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00webook\x00secret")), r.auth)
+	assert.Equal(t, "MAIL FROM:<noreply@webook.com>", r.from)
+	assert.Equal(t, []string{"RCPT TO:<a@qq.com>", "RCPT TO:<b@qq.com>"}, r.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(r.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "webook 验证码", subject)
+	assert.Equal(t, "a@qq.com, b@qq.com", msg.Header.Get("To"))
+	body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, msg.Body))
+	require.NoError(t, err)
+	assert.Equal(t, "您的验证码是 123456", string(body))
+}
+
+func TestService_Send_RejectRcpt(t *testing.T) {
+	host, port, _ := newStubServer(t, "unknown@qq.com")
+	svc := NewService(host, port, "", "", "noreply@webook.com", time.Second)
+
+	err := svc.Send(context.Background(), "webook 验证码", "您的验证码是 123456", "unknown@qq.com")
+	assert.ErrorContains(t, err, "No such user")
+}
+
+func TestService_Send_Timeout(t *testing.T) {
+	// 接受连接之后一直不响应
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	svc := NewService(host, p, "", "", "noreply@webook.com", 100*time.Millisecond)
+
+	// 与 gin 的请求 ctx 一样没有 deadline
+	start := time.Now()
+	err = svc.Send(context.Background(), "webook 验证码", "您的验证码是 123456", "a@qq.com")
+	var ne net.Error
+	require.ErrorAs(t, err, &ne)
+	assert.True(t, ne.Timeout())
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+var ErrLoginLocked = errors.New("登录失败次数过多，暂时锁定")
+
+// 失败次数分别按照账号与 IP 统计
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+)
+
+// LoginLockPolicies 账号与 IP 的锁定策略
+type LoginLockPolicies struct {
+	Account domain.LoginLockPolicy
+	IP      domain.LoginLockPolicy
+}
+
+// LoginGuardService 密码登录的防暴力破解，account 是登录使用的账号，例如邮箱
+type LoginGuardService interface {
+	// Check 账号或者 IP 已经被锁定时返回 ErrLoginLocked 与剩余的锁定时间
+	Check(ctx context.Context, account, ip string) (time.Duration, error)
+	// Fail 记录一次登录失败，达到阈值之后锁定，返回 ErrLoginLocked 与锁定时长
+	Fail(ctx context.Context, account, ip string) (time.Duration, error)
+	// Reset 登录成功或者通过短信验证之后清空账号的失败记录，IP 的记录不受影响
+	Reset(ctx context.Context, account string) error
+}
+
+type loginGuardService struct {
+	repo     repository.LoginLockRepository
+	policies LoginLockPolicies
+}
+
+func NewLoginGuardService(repo repository.LoginLockRepository, policies LoginLockPolicies) LoginGuardService {
+	return &loginGuardService{repo: repo, policies: policies}
+}
+
+func (svc *loginGuardService) Check(ctx context.Context, account, ip string) (time.Duration, error) {
+	account = normalizeAccount(account)
+	accountTTL, err := svc.repo.LockTTL(ctx, loginScopeAccount, account)
+	if err != nil {
+		return 0, err
+	}
+
+	ipTTL, err := svc.repo.LockTTL(ctx, loginScopeIP, ip)
+	if err != nil {
+		return 0, err
+	}
+
+	if ttl := max(accountTTL, ipTTL); ttl > 0 {
+		return ttl, ErrLoginLocked
+	}
+	return 0, nil
+}
+
+func (svc *loginGuardService) Fail(ctx context.Context, account, ip string) (time.Duration, error) {
+	account = normalizeAccount(account)
+	accountLock, err := svc.repo.Fail(ctx, loginScopeAccount, account, svc.policies.Account)
+	if err != nil {
+		return 0, err
+	}
+
+	ipLock, err := svc.repo.Fail(ctx, loginScopeIP, ip, svc.policies.IP)
+	if err != nil {
+		return 0, err
+	}
+
+	if lock := max(accountLock, ipLock); lock > 0 {
+		return lock, ErrLoginLocked
+	}
+	return 0, nil
+}
+
+func (svc *loginGuardService) Reset(ctx context.Context, account string) error {
+	return svc.repo.Reset(ctx, loginScopeAccount, normalizeAccount(account))
+}
+
+// 数据库中邮箱的比较不区分大小写，大小写不同的邮箱登录的是同一个账号，需要计入同一个计数器
+func normalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	repomocks "webook/internal/repository/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var testLockPolicies = LoginLockPolicies{
+	Account: domain.LoginLockPolicy{Threshold: 5, BaseLock: time.Minute, MaxLock: time.Hour, Window: 2 * time.Hour},
+	IP:      domain.LoginLockPolicy{Threshold: 50, BaseLock: time.Minute, MaxLock: time.Hour, Window: 2 * time.Hour},
+}
+
+func TestLoginGuardService_Check(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository
+		wantTTL time.Duration
+		wantErr error
+	}{
+		{
+			name: "没有锁定",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository {
+				repo := repomocks.NewMockLoginLockRepository(ctrl)
+				repo.EXPECT().LockTTL(gomock.Any(), "account", "abc@qq.com").Return(time.Duration(0), nil)
+				repo.EXPECT().LockTTL(gomock.Any(), "ip", "127.0.0.1").Return(time.Duration(0), nil)
+				return repo
+			},
+		},
+		{
+			name: "IP 被锁定",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository {
+				repo := repomocks.NewMockLoginLockRepository(ctrl)
+				repo.EXPECT().LockTTL(gomock.Any(), "account", "abc@qq.com").Return(time.Minute, nil)
+				repo.EXPECT().LockTTL(gomock.Any(), "ip", "127.0.0.1").Return(10*time.Minute, nil)
+				return repo
+			},
+			wantTTL: 10 * time.Minute,
+			wantErr: ErrLoginLocked,
+		},
+		{
+			name: "redis 错误",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository {
+				repo := repomocks.NewMockLoginLockRepository(ctrl)
+				repo.EXPECT().LockTTL(gomock.Any(), "account", "abc@qq.com").
+					Return(time.Duration(0), errors.New("mock redis error"))
+				return repo
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewLoginGuardService(tc.mock(ctrl), testLockPolicies)
+			ttl, err := svc.Check(context.Background(), "abc@qq.com", "127.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTTL, ttl)
+		})
+	}
+}
+
+func TestLoginGuardService_Fail(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository
+		wantLock time.Duration
+		wantErr  error
+	}{
+		{
+			name: "没有达到阈值",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository {
+				repo := repomocks.NewMockLoginLockRepository(ctrl)
+				repo.EXPECT().Fail(gomock.Any(), "account", "abc@qq.com", testLockPolicies.Account).
+					Return(time.Duration(0), nil)
+				repo.EXPECT().Fail(gomock.Any(), "ip", "127.0.0.1", testLockPolicies.IP).
+					Return(time.Duration(0), nil)
+				return repo
+			},
+		},
+		{
+			name: "账号被锁定",
+			mock: func(ctrl *gomock.Controller) *repomocks.MockLoginLockRepository {
+				repo := repomocks.NewMockLoginLockRepository(ctrl)
+				repo.EXPECT().Fail(gomock.Any(), "account", "abc@qq.com", testLockPolicies.Account).
+					Return(2*time.Minute, nil)
+				repo.EXPECT().Fail(gomock.Any(), "ip", "127.0.0.1", testLockPolicies.IP).
+					Return(time.Duration(0), nil)
+				return repo
+			},
+			wantLock: 2 * time.Minute,
+			wantErr:  ErrLoginLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewLoginGuardService(tc.mock(ctrl), testLockPolicies)
+			// 大小写不同、带空格的邮箱计入同一个计数器
+			lock, err := svc.Fail(context.Background(), " ABC@qq.com", "127.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLock, lock)
+		})
+	}
+}
+
+func TestLoginGuardService_Reset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repomocks.NewMockLoginLockRepository(ctrl)
+	repo.EXPECT().Reset(gomock.Any(), "account", "abc@qq.com").Return(nil)
+	svc := NewLoginGuardService(repo, testLockPolicies)
+	assert.NoError(t, svc.Reset(context.Background(), "Abc@QQ.com"))
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: C:\Users\xuche\Desktop\Code\golang\webook\internal\service\code.go
+// Source: ./internal/service/code.go
 //
 // Generated by this command:
 //
-//	mockgen -source=C:\Users\xuche\Desktop\Code\golang\webook\internal\service\code.go -package=svcmocks -destination=C:\Users\xuche\Desktop\Code\golang\webook\internal\service\mock\code.mock.go
+//	mockgen -source=./internal/service/code.go -package=svcmocks -destination=./internal/service/mock/code.mock.go
 //
 
 // Package svcmocks is a generated GoMock package.
@@ -12,6 +12,7 @@ package svcmocks
 import (
 	context "context"
 	reflect "reflect"
+	domain "webook/internal/domain"
 
 	gin "github.com/gin-gonic/gin"
 	gomock "go.uber.org/mock/gomock"
@@ -42,29 +43,133 @@ func (m *MockCodeService) EXPECT() *MockCodeServiceMockRecorder {
 }
 
 // Send mocks base method.
-func (m *MockCodeService) Send(ctx context.Context, biz, phone string) error {
+func (m *MockCodeService) Send(ctx context.Context, biz, target string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", ctx, biz, phone)
+	ret := m.ctrl.Call(m, "Send", ctx, biz, target)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Send indicates an expected call of Send.
-func (mr *MockCodeServiceMockRecorder) Send(ctx, biz, phone any) *gomock.Call {
+func (mr *MockCodeServiceMockRecorder) Send(ctx, biz, target any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, biz, phone)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, biz, target)
 }
 
 // Verify mocks base method.
-func (m *MockCodeService) Verify(c *gin.Context, biz, phone, inputCode string) error {
+func (m *MockCodeService) Verify(c *gin.Context, biz, target, inputCode string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Verify", c, biz, phone, inputCode)
+	ret := m.ctrl.Call(m, "Verify", c, biz, target, inputCode)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Verify indicates an expected call of Verify.
-func (mr *MockCodeServiceMockRecorder) Verify(c, biz, phone, inputCode any) *gomock.Call {
+func (mr *MockCodeServiceMockRecorder) Verify(c, biz, target, inputCode any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeService)(nil).Verify), c, biz, phone, inputCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeService)(nil).Verify), c, biz, target, inputCode)
+}
+
+// MockEmailCodeService is a mock of EmailCodeService interface.
+type MockEmailCodeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailCodeServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailCodeServiceMockRecorder is the mock recorder for MockEmailCodeService.
+type MockEmailCodeServiceMockRecorder struct {
+	mock *MockEmailCodeService
+}
+
+// NewMockEmailCodeService creates a new mock instance.
+func NewMockEmailCodeService(ctrl *gomock.Controller) *MockEmailCodeService {
+	mock := &MockEmailCodeService{ctrl: ctrl}
+	mock.recorder = &MockEmailCodeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailCodeService) EXPECT() *MockEmailCodeServiceMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockEmailCodeService) Send(ctx context.Context, biz, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, biz, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockEmailCodeServiceMockRecorder) Send(ctx, biz, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockEmailCodeService)(nil).Send), ctx, biz, target)
+}
+
+// Verify mocks base method.
+func (m *MockEmailCodeService) Verify(c *gin.Context, biz, target, inputCode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", c, biz, target, inputCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockEmailCodeServiceMockRecorder) Verify(c, biz, target, inputCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockEmailCodeService)(nil).Verify), c, biz, target, inputCode)
+}
+
+// MockCodeChannel is a mock of CodeChannel interface.
+type MockCodeChannel struct {
+	ctrl     *gomock.Controller
+	recorder *MockCodeChannelMockRecorder
+	isgomock struct{}
+}
+
+// MockCodeChannelMockRecorder is the mock recorder for MockCodeChannel.
+type MockCodeChannelMockRecorder struct {
+	mock *MockCodeChannel
+}
+
+// NewMockCodeChannel creates a new mock instance.
+func NewMockCodeChannel(ctrl *gomock.Controller) *MockCodeChannel {
+	mock := &MockCodeChannel{ctrl: ctrl}
+	mock.recorder = &MockCodeChannelMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCodeChannel) EXPECT() *MockCodeChannelMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method.
+func (m *MockCodeChannel) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockCodeChannelMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockCodeChannel)(nil).Name))
+}
+
+// Send mocks base method.
+func (m *MockCodeChannel) Send(ctx context.Context, biz, target, code string, policy domain.CodePolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, biz, target, code, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockCodeChannelMockRecorder) Send(ctx, biz, target, code, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeChannel)(nil).Send), ctx, biz, target, code, policy)
 }
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/sms_log.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/sms_log.go -package=svcmocks -destination=./internal/service/mock/sms_log.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSmsLogService is a mock of SmsLogService interface.
+type MockSmsLogService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSmsLogServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockSmsLogServiceMockRecorder is the mock recorder for MockSmsLogService.
+type MockSmsLogServiceMockRecorder struct {
+	mock *MockSmsLogService
+}
+
+// NewMockSmsLogService creates a new mock instance.
+func NewMockSmsLogService(ctrl *gomock.Controller) *MockSmsLogService {
+	mock := &MockSmsLogService{ctrl: ctrl}
+	mock.recorder = &MockSmsLogServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSmsLogService) EXPECT() *MockSmsLogServiceMockRecorder {
+	return m.recorder
+}
+
+// AsyncStats mocks base method.
+func (m *MockSmsLogService) AsyncStats(ctx context.Context) (domain.AsyncSmsStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AsyncStats", ctx)
+	ret0, _ := ret[0].(domain.AsyncSmsStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AsyncStats indicates an expected call of AsyncStats.
+func (mr *MockSmsLogServiceMockRecorder) AsyncStats(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AsyncStats", reflect.TypeOf((*MockSmsLogService)(nil).AsyncStats), ctx)
+}
+
+// Find mocks base method.
+func (m *MockSmsLogService) Find(ctx context.Context, q domain.SmsLogQuery) ([]domain.SmsLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, q)
+	ret0, _ := ret[0].([]domain.SmsLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockSmsLogServiceMockRecorder) Find(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockSmsLogService)(nil).Find), ctx, q)
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/totp.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/totp.go -package=svcmocks -destination=./internal/service/mock/totp.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTotpService is a mock of TotpService interface.
+type MockTotpService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTotpServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockTotpServiceMockRecorder is the mock recorder for MockTotpService.
+type MockTotpServiceMockRecorder struct {
+	mock *MockTotpService
+}
+
+// NewMockTotpService creates a new mock instance.
+func NewMockTotpService(ctrl *gomock.Controller) *MockTotpService {
+	mock := &MockTotpService{ctrl: ctrl}
+	mock.recorder = &MockTotpServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTotpService) EXPECT() *MockTotpServiceMockRecorder {
+	return m.recorder
+}
+
+// Enable mocks base method.
+func (m *MockTotpService) Enable(ctx context.Context, uid int64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockTotpServiceMockRecorder) Enable(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockTotpService)(nil).Enable), ctx, uid, code)
+}
+
+// Enabled mocks base method.
+func (m *MockTotpService) Enabled(ctx context.Context, uid int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled", ctx, uid)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockTotpServiceMockRecorder) Enabled(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockTotpService)(nil).Enabled), ctx, uid)
+}
+
+// Setup mocks base method.
+func (m *MockTotpService) Setup(ctx context.Context, uid int64) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Setup", ctx, uid)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Setup indicates an expected call of Setup.
+func (mr *MockTotpServiceMockRecorder) Setup(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Setup", reflect.TypeOf((*MockTotpService)(nil).Setup), ctx, uid)
+}
+
+// Verify mocks base method.
+func (m *MockTotpService) Verify(ctx context.Context, uid int64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, uid, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockTotpServiceMockRecorder) Verify(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTotpService)(nil).Verify), ctx, uid, code)
+}
@@ -0,0 +1,112 @@
+package wechat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"webook/internal/domain"
+)
+
+// 微信开放平台网站应用扫码登录
+const authURLPattern = "https://open.weixin.qq.com/connect/qrconnect?appid=%s&redirect_uri=%s" +
+	"&response_type=code&scope=snsapi_login&state=%s#wechat_redirect"
+
+// DefaultAPIBase 微信接口的地址，测试时替换成本地的假服务
+const DefaultAPIBase = "https://api.weixin.qq.com"
+
+// ErrNotConfigured 没有配置 AppID，微信登录没有启用
+var ErrNotConfigured = errors.New("没有配置微信的 AppID")
+
+// Service 封装微信 OAuth2 的授权码流程
+type Service interface {
+	// AuthURL 返回跳转到微信扫码页面的地址，state 会原样带回回调地址
+	AuthURL(ctx context.Context, state string) (string, error)
+	// VerifyCode 使用回调中的授权码换取用户标识
+	VerifyCode(ctx context.Context, code string) (domain.WechatInfo, error)
+}
+
+type service struct {
+	appId       string
+	appSecret   string
+	redirectURL string
+	apiBase     string
+	client      *http.Client
+}
+
+func NewService(appId, appSecret, redirectURL, apiBase string, client *http.Client) Service {
+	return &service{
+		appId:       appId,
+		appSecret:   appSecret,
+		redirectURL: redirectURL,
+		apiBase:     apiBase,
+		client:      client,
+	}
+}
+
+func (s *service) AuthURL(ctx context.Context, state string) (string, error) {
+	if s.appId == "" {
+		return "", ErrNotConfigured
+	}
+	return fmt.Sprintf(authURLPattern, s.appId, url.QueryEscape(s.redirectURL), url.QueryEscape(state)), nil
+}
+
+func (s *service) VerifyCode(ctx context.Context, code string) (domain.WechatInfo, error) {
+	if s.appId == "" {
+		return domain.WechatInfo{}, ErrNotConfigured
+	}
+	q := url.Values{}
+	q.Set("appid", s.appId)
+	q.Set("secret", s.appSecret)
+	q.Set("code", code)
+	q.Set("grant_type", "authorization_code")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		s.apiBase+"/sns/oauth2/access_token?"+q.Encode(), nil)
+	if err != nil {
+		return domain.WechatInfo{}, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return domain.WechatInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return domain.WechatInfo{}, fmt.Errorf("微信接口返回 HTTP %d", resp.StatusCode)
+	}
+
+	var res Result
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return domain.WechatInfo{}, err
+	}
+
+	// 出错时 HTTP 状态码仍然是 200，错误信息在 errcode 中
+	if res.ErrCode != 0 {
+		return domain.WechatInfo{}, fmt.Errorf("换取 access_token 失败 %d %s", res.ErrCode, res.ErrMsg)
+	}
+	// 没有 openid 时按照空的 openid 查找用户，所有这样的回调都会登录到同一个账号
+	if res.OpenId == "" {
+		return domain.WechatInfo{}, errors.New("微信没有返回 openid")
+	}
+
+	return domain.WechatInfo{
+		OpenId:  res.OpenId,
+		UnionId: res.UnionId,
+	}, nil
+}
+
+// Result 微信换取 access_token 接口的响应
+type Result struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	OpenId       string `json:"openid"`
+	Scope        string `json:"scope"`
+	UnionId      string `json:"unionid"`
+
+	ErrCode int64  `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
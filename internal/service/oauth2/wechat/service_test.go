@@ -0,0 +1,67 @@
+package wechat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_VerifyCode(t *testing.T) {
+	// 本地的假微信接口
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sns/oauth2/access_token", r.URL.Path)
+		assert.Equal(t, "app-id", r.URL.Query().Get("appid"))
+		assert.Equal(t, "app-secret", r.URL.Query().Get("secret"))
+		assert.Equal(t, "authorization_code", r.URL.Query().Get("grant_type"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("code") {
+		case "good-code":
+			_, _ = w.Write([]byte(`{"access_token":"at","expires_in":7200,"refresh_token":"rt",` +
+				`"openid":"open-id","scope":"snsapi_login","unionid":"union-id"}`))
+		case "no-openid-code":
+			_, _ = w.Write([]byte(`{"access_token":"at","expires_in":7200,"refresh_token":"rt",` +
+				`"scope":"snsapi_login"}`))
+		default:
+			_, _ = w.Write([]byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+		}
+	}))
+	defer server.Close()
+
+	svc := NewService("app-id", "app-secret", "https://webook.com/oauth2/wechat/callback",
+		server.URL, server.Client())
+
+	info, err := svc.VerifyCode(context.Background(), "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, domain.WechatInfo{OpenId: "open-id", UnionId: "union-id"}, info)
+
+	_, err = svc.VerifyCode(context.Background(), "bad-code")
+	assert.ErrorContains(t, err, "40029")
+
+	_, err = svc.VerifyCode(context.Background(), "no-openid-code")
+	assert.ErrorContains(t, err, "openid")
+}
+
+func TestService_NotConfigured(t *testing.T) {
+	svc := NewService("", "", "https://webook.com/oauth2/wechat/callback",
+		DefaultAPIBase, http.DefaultClient)
+	_, err := svc.AuthURL(context.Background(), "state-1")
+	assert.ErrorIs(t, err, ErrNotConfigured)
+	_, err = svc.VerifyCode(context.Background(), "good-code")
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestService_AuthURL(t *testing.T) {
+	svc := NewService("app-id", "app-secret", "https://webook.com/oauth2/wechat/callback",
+		DefaultAPIBase, http.DefaultClient)
+	u, err := svc.AuthURL(context.Background(), "state-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://open.weixin.qq.com/connect/qrconnect?appid=app-id"+
+		"&redirect_uri=https%3A%2F%2Fwebook.com%2Foauth2%2Fwechat%2Fcallback"+
+		"&response_type=code&scope=snsapi_login&state=state-1#wechat_redirect", u)
+}
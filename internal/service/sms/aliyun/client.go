@@ -0,0 +1,128 @@
+package aliyun
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// 阿里云短信 API 的默认地址
+	DefaultEndpoint = "https://dysmsapi.aliyuncs.com"
+	apiVersion      = "2017-05-25"
+)
+
+// Client 调用阿里云短信的 RPC 风格 API，签名算法参考
+// https://help.aliyun.com/document_detail/101343.html
+type Client struct {
+	endpoint        string
+	regionId        string
+	accessKeyId     string
+	accessKeySecret string
+	httpClient      *http.Client
+}
+
+func NewClient(endpoint, regionId, accessKeyId, accessKeySecret string) *Client {
+	return &Client{
+		endpoint:        endpoint,
+		regionId:        regionId,
+		accessKeyId:     accessKeyId,
+		accessKeySecret: accessKeySecret,
+		httpClient:      &http.Client{Timeout: time.Second * 5},
+	}
+}
+
+type SendSmsRequest struct {
+	PhoneNumbers  string
+	SignName      string
+	TemplateCode  string
+	TemplateParam string
+}
+
+type SendSmsResponse struct {
+	RequestId string `json:"RequestId"`
+	BizId     string `json:"BizId"`
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+}
+
+func (c *Client) SendSms(ctx context.Context, req SendSmsRequest) (SendSmsResponse, error) {
+	params := map[string]string{
+		"Action":           "SendSms",
+		"Version":          apiVersion,
+		"Format":           "JSON",
+		"RegionId":         c.regionId,
+		"AccessKeyId":      c.accessKeyId,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   uuid.New().String(),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"PhoneNumbers":     req.PhoneNumbers,
+		"SignName":         req.SignName,
+		"TemplateCode":     req.TemplateCode,
+		"TemplateParam":    req.TemplateParam,
+	}
+	query := canonicalize(params)
+	signature := Sign(http.MethodGet, query, c.accessKeySecret)
+	reqURL := c.endpoint + "/?Signature=" + percentEncode(signature) + "&" + query
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return SendSmsResponse{}, err
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return SendSmsResponse{}, err
+	}
+	defer httpResp.Body.Close()
+
+	// 业务错误也会返回 JSON，状态码可能不是 200
+	var resp SendSmsResponse
+	if err = json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return SendSmsResponse{}, fmt.Errorf("解析阿里云短信响应失败, status %d: %w", httpResp.StatusCode, err)
+	}
+
+	return resp, nil
+}
+
+// Sign 计算请求签名，query 为按照参数名排序并编码之后的请求参数
+func Sign(method, query, accessKeySecret string) string {
+	stringToSign := method + "&" + percentEncode("/") + "&" + percentEncode(query)
+	mac := hmac.New(sha1.New, []byte(accessKeySecret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func canonicalize(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(params[k]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// 阿里云要求的 RFC 3986 编码
+func percentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	s = strings.ReplaceAll(s, "%7E", "~")
+	return s
+}
@@ -1 +1,71 @@
 package aliyun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"webook/internal/service/sms"
+)
+
+// 阿里云在 Templates 中的服务商名称
+const provider = "aliyun"
+
+type Service struct {
+	client    *Client
+	signName  string
+	templates *sms.Templates
+}
+
+func NewService(client *Client, signName string, templates *sms.Templates) *Service {
+	return &Service{
+		client:    client,
+		signName:  signName,
+		templates: templates,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	t, err := s.templates.Find(tpl)
+	if err != nil {
+		return err
+	}
+
+	code, err := t.ProviderId(provider)
+	if err != nil {
+		return err
+	}
+
+	// 阿里云的模板参数是具名的 JSON
+	tplParam, err := json.Marshal(t.Named(args))
+	if err != nil {
+		return err
+	}
+
+	// 阿里云批量发送只返回一个状态，逐个号码发送才能知道每个号码的发送结果
+	var failed []string
+	var errs []error
+	for _, number := range numbers {
+		resp, err := s.client.SendSms(ctx, SendSmsRequest{
+			PhoneNumbers:  number,
+			SignName:      s.signName,
+			TemplateCode:  code,
+			TemplateParam: string(tplParam),
+		})
+		if err != nil {
+			failed = append(failed, number)
+			errs = append(errs, fmt.Errorf("发送短信失败 %s: %w", number, err))
+			continue
+		}
+
+		if resp.Code != "OK" {
+			failed = append(failed, number)
+			errs = append(errs, fmt.Errorf("发送短信失败 %s: %s, %s", number, resp.Code, resp.Message))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &sms.PartialError{Numbers: failed, Err: errors.Join(errs...)}
+}
@@ -0,0 +1,183 @@
+package aliyun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"webook/internal/service/sms"
+	"webook/internal/service/sms/failover"
+	smsmocks "webook/internal/service/sms/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testAccessKeyId     = "test-key-id"
+	testAccessKeySecret = "test-key-secret"
+)
+
+// 模拟阿里云短信 API，校验签名与参数
+func newFakeServer(t *testing.T, codes map[string]string) (*httptest.Server, *[]url.Values) {
+	var reqs []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		reqs = append(reqs, query)
+
+		signature := query.Get("Signature")
+		query.Del("Signature")
+		// url.Values.Encode 按照 key 排序，编码方式需要转换成 RFC 3986
+		encoded := strings.NewReplacer("+", "%20", "*", "%2A", "%7E", "~").Replace(query.Encode())
+		if signature != Sign(http.MethodGet, encoded, testAccessKeySecret) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(SendSmsResponse{
+				Code:    "SignatureDoesNotMatch",
+				Message: "Specified signature is not matched with our calculation.",
+			})
+			return
+		}
+
+		code, ok := codes[query.Get("PhoneNumbers")]
+		if !ok {
+			code = "OK"
+		}
+		_ = json.NewEncoder(w).Encode(SendSmsResponse{
+			RequestId: "request-id",
+			Code:      code,
+			Message:   code,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &reqs
+}
+
+func TestService_Send(t *testing.T) {
+	templates := sms.NewTemplates(sms.Template{
+		Name:        "login_code",
+		Params:      []string{"code"},
+		ProviderIds: map[string]string{"aliyun": "SMS_123456"},
+	}, sms.Template{
+		Name:        "notice",
+		Params:      []string{"content"},
+		ProviderIds: map[string]string{"tencent": "1877557"},
+	})
+
+	testCases := []struct {
+		name string
+
+		codes   map[string]string
+		tpl     string
+		args    []sms.NamedArg
+		numbers []string
+		wantErr string
+		// 发送失败的号码，故障转移时只重发这些号码
+		wantFailed []string
+		// 期望发出的请求数
+		wantReqs int
+	}{
+		{
+			name:     "发送成功",
+			tpl:      "login_code",
+			args:     []sms.NamedArg{{Name: "code", Val: "123456"}},
+			numbers:  []string{"13812345678"},
+			wantReqs: 1,
+		},
+		{
+			name:  "部分号码发送失败",
+			codes: map[string]string{"13800000000": "isv.MOBILE_NUMBER_ILLEGAL"},
+			tpl:   "login_code",
+			args:  []sms.NamedArg{{Name: "code", Val: "123456"}},
+			numbers: []string{
+				"13812345678", "13800000000",
+			},
+			wantErr:    "13800000000: isv.MOBILE_NUMBER_ILLEGAL",
+			wantFailed: []string{"13800000000"},
+			wantReqs:   2,
+		},
+		{
+			name:    "模板不存在",
+			tpl:     "unknown",
+			args:    []sms.NamedArg{{Name: "code", Val: "123456"}},
+			numbers: []string{"13812345678"},
+			wantErr: "短信模板不存在",
+		},
+		{
+			name:    "模板没有配置阿里云的模板 id",
+			tpl:     "notice",
+			args:    []sms.NamedArg{{Name: "content", Val: "hello"}},
+			numbers: []string{"13812345678"},
+			wantErr: "没有配置 aliyun 的模板 id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, reqs := newFakeServer(t, tc.codes)
+			client := NewClient(server.URL, "cn-hangzhou", testAccessKeyId, testAccessKeySecret)
+			svc := NewService(client, "webook", templates)
+
+			err := svc.Send(context.Background(), tc.tpl, tc.args, tc.numbers...)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.wantFailed != nil {
+				var pe *sms.PartialError
+				require.ErrorAs(t, err, &pe)
+				assert.Equal(t, tc.wantFailed, pe.Numbers)
+			}
+
+			require.Len(t, *reqs, tc.wantReqs)
+			for _, req := range *reqs {
+				assert.Equal(t, "SendSms", req.Get("Action"))
+				assert.Equal(t, testAccessKeyId, req.Get("AccessKeyId"))
+				assert.Equal(t, "webook", req.Get("SignName"))
+				assert.Equal(t, "SMS_123456", req.Get("TemplateCode"))
+				assert.JSONEq(t, `{"code":"123456"}`, req.Get("TemplateParam"))
+			}
+		})
+	}
+}
+
+func TestService_Send_Failover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	templates := sms.NewTemplates(sms.Template{
+		Name:        "login_code",
+		Params:      []string{"code"},
+		ProviderIds: map[string]string{"aliyun": "SMS_123456"},
+	})
+	server, _ := newFakeServer(t, map[string]string{"13800000000": "isv.BUSINESS_LIMIT_CONTROL"})
+	svc := NewService(NewClient(server.URL, "cn-hangzhou", testAccessKeyId, testAccessKeySecret),
+		"webook", templates)
+
+	// 已经收到验证码的号码不会再收到一条
+	args := []sms.NamedArg{{Name: "code", Val: "123456"}}
+	backup := smsmocks.NewMockService(ctrl)
+	backup.EXPECT().Send(gomock.Any(), "login_code", args, "13800000000").Return(nil)
+
+	err := failover.NewFailoverService([]sms.Service{svc, backup}).
+		Send(context.Background(), "login_code", args, "13812345678", "13800000000")
+	assert.NoError(t, err)
+}
+
+func TestClient_SendSms_BadSignature(t *testing.T) {
+	server, _ := newFakeServer(t, nil)
+	client := NewClient(server.URL, "cn-hangzhou", testAccessKeyId, "wrong-secret")
+
+	resp, err := client.SendSms(context.Background(), SendSmsRequest{
+		PhoneNumbers: "13812345678",
+		SignName:     "webook",
+		TemplateCode: "SMS_123456",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SignatureDoesNotMatch", resp.Code)
+}
@@ -0,0 +1,237 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/internal/service/sms"
+)
+
+type Options struct {
+	// 最近多少次发送结果用于判断服务商是否健康
+	WindowSize int
+	// 样本数少于这个值时认为服务商健康
+	MinSamples int
+	// 错误率超过阈值认为服务商不健康
+	ErrRateThreshold float64
+	// 平均响应时间超过阈值认为服务商不健康
+	LatencyThreshold time.Duration
+
+	// 后台重试的 worker 数量
+	Workers int
+	// 没有待发送的短信时，worker 轮询数据库的间隔
+	PollInterval time.Duration
+	// 每条短信最多尝试发送的次数
+	RetryMax int
+	// 重试间隔从 BaseBackoff 开始翻倍，最多 MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// 单次发送的超时时间，同时也是抢占记录的租约时间
+	SendTimeout time.Duration
+}
+
+// 一次发送的结果
+type sample struct {
+	failed  bool
+	latency time.Duration
+}
+
+// Service 服务商健康时同步发送；不健康时把短信保存到数据库，
+// 由后台 worker 按照指数退避的间隔重试，避免请求卡在服务商上
+type Service struct {
+	svc  sms.Service
+	repo repository.AsyncSmsRepository
+	opts Options
+
+	// 最近 WindowSize 次发送结果，环形数组
+	mu      sync.Mutex
+	samples []sample
+	next    int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewService(svc sms.Service, repo repository.AsyncSmsRepository, opts Options) *Service {
+	return &Service{
+		svc:     svc,
+		repo:    repo,
+		opts:    opts,
+		samples: make([]sample, 0, opts.WindowSize),
+		stop:    make(chan struct{}),
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	if !s.healthy() {
+		// 服务商不健康，转为异步发送
+		return s.repo.Add(ctx, domain.AsyncSms{
+			Biz:      sms.BizFromContext(ctx),
+			Tpl:      tpl,
+			Args:     toMap(args),
+			Numbers:  numbers,
+			RetryMax: s.opts.RetryMax,
+		})
+	}
+
+	return s.send(ctx, tpl, args, numbers...)
+}
+
+// 启动后台重试的 worker
+func (s *Service) Start() {
+	for i := 0; i < s.opts.Workers; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.work()
+		}()
+	}
+}
+
+// 停止 worker，等待正在发送的短信完成
+func (s *Service) Stop(ctx context.Context) error {
+	close(s.stop)
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) work() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		if s.sendOne() {
+			continue
+		}
+
+		// 没有待发送的短信或者数据库出错，休息一会儿
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(s.opts.PollInterval):
+		}
+	}
+}
+
+// 抢占并发送一条短信，返回是否抢占到了短信
+func (s *Service) sendOne() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.SendTimeout)
+	defer cancel()
+
+	as, err := s.repo.PreemptWaitingSMS(ctx, s.opts.SendTimeout)
+	if err != nil {
+		if !errors.Is(err, repository.ErrWaitingSMSNotFound) {
+			log.Println("抢占异步短信失败......", err)
+		}
+		return false
+	}
+
+	err = s.send(sms.WithBiz(ctx, as.Biz), as.Tpl, toNamedArgs(as.Args), as.Numbers...)
+	// 上报结果不能受发送超时的影响
+	rctx, rcancel := context.WithTimeout(context.Background(), time.Second)
+	defer rcancel()
+	var pe *sms.PartialError
+	if errors.As(err, &pe) && len(pe.Numbers) > 0 {
+		// 已经发送成功的号码不再重发，失败记录中也只保留没有收到的号码
+		as.Numbers = pe.Numbers
+	}
+
+	switch {
+	case err == nil:
+		err = s.repo.ReportSuccess(rctx, as)
+	case as.RetryCnt >= as.RetryMax:
+		log.Println("异步短信重试次数用完......", as.Id, err)
+		err = s.repo.ReportFailed(rctx, as)
+	default:
+		err = s.repo.ReportRetry(rctx, as, time.Now().Add(s.backoff(as.RetryCnt)))
+	}
+
+	if err != nil {
+		// 上报失败，租约到期之后会被重新发送
+		log.Println("更新异步短信状态失败......", as.Id, err)
+	}
+	return true
+}
+
+// 第 n 次失败之后的重试间隔
+func (s *Service) backoff(retryCnt int) time.Duration {
+	d := s.opts.BaseBackoff
+	for i := 1; i < retryCnt && d < s.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, s.opts.MaxBackoff)
+}
+
+func (s *Service) send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	start := time.Now()
+	err := s.svc.Send(ctx, tpl, args, numbers...)
+	s.record(sample{failed: err != nil, latency: time.Since(start)})
+	return err
+}
+
+// 同步发送与后台重试的结果都会记录，服务商恢复之后自动切回同步发送
+func (s *Service) record(smp sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.samples) < s.opts.WindowSize {
+		s.samples = append(s.samples, smp)
+		return
+	}
+
+	s.samples[s.next] = smp
+	s.next = (s.next + 1) % s.opts.WindowSize
+}
+
+func (s *Service) healthy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.samples) < s.opts.MinSamples {
+		return true
+	}
+
+	var failed int
+	var latency time.Duration
+	for _, smp := range s.samples {
+		if smp.failed {
+			failed++
+		}
+		latency += smp.latency
+	}
+
+	errRate := float64(failed) / float64(len(s.samples))
+	avgLatency := latency / time.Duration(len(s.samples))
+	return errRate <= s.opts.ErrRateThreshold && avgLatency <= s.opts.LatencyThreshold
+}
+
+func toMap(args []sms.NamedArg) map[string]string {
+	res := make(map[string]string, len(args))
+	for _, arg := range args {
+		res[arg.Name] = arg.Val
+	}
+	return res
+}
+
+func toNamedArgs(args map[string]string) []sms.NamedArg {
+	res := make([]sms.NamedArg, 0, len(args))
+	for name, val := range args {
+		res = append(res, sms.NamedArg{Name: name, Val: val})
+	}
+	return res
+}
@@ -0,0 +1,206 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mock"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var testOpts = Options{
+	WindowSize:       10,
+	MinSamples:       2,
+	ErrRateThreshold: 0.5,
+	LatencyThreshold: time.Second,
+	Workers:          1,
+	PollInterval:     time.Millisecond,
+	RetryMax:         3,
+	BaseBackoff:      time.Second,
+	MaxBackoff:       4 * time.Second,
+	SendTimeout:      time.Second,
+}
+
+func TestService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository)
+		samples []sample
+		wantErr error
+	}{
+		{
+			name: "服务商健康，同步发送",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(nil)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			samples: []sample{{}, {}},
+		},
+		{
+			name: "样本不足，同步发送失败",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(errors.New("发送失败"))
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			samples: []sample{{failed: true}},
+			wantErr: errors.New("发送失败"),
+		},
+		{
+			name: "错误率过高，转为异步",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), domain.AsyncSms{
+					Tpl:      "login_code",
+					Args:     map[string]string{"code": "123456"},
+					Numbers:  []string{"13812345678"},
+					RetryMax: 3,
+				}).Return(nil)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			samples: []sample{{failed: true}, {failed: true}, {}},
+		},
+		{
+			name: "响应时间过长，转为异步",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			samples: []sample{{latency: 3 * time.Second}, {latency: time.Millisecond}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc, repo := tc.mock(ctrl)
+			s := NewService(svc, repo, testOpts)
+			for _, smp := range tc.samples {
+				s.record(smp)
+			}
+
+			err := s.Send(context.Background(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestService_sendOne(t *testing.T) {
+	as := domain.AsyncSms{
+		Id:       1,
+		Tpl:      "login_code",
+		Args:     map[string]string{"code": "123456"},
+		Numbers:  []string{"13812345678"},
+		RetryCnt: 1,
+		RetryMax: 3,
+	}
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository)
+		want bool
+	}{
+		{
+			name: "没有待发送的短信",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any(), time.Second).
+					Return(domain.AsyncSms{}, repository.ErrWaitingSMSNotFound)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+		},
+		{
+			name: "发送成功",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any(), time.Second).Return(as, nil)
+				repo.EXPECT().ReportSuccess(gomock.Any(), as).Return(nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(nil)
+				return svc, repo
+			},
+			want: true,
+		},
+		{
+			name: "发送失败，稍后重试",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any(), time.Second).Return(as, nil)
+				repo.EXPECT().ReportRetry(gomock.Any(), as, gomock.Any()).Return(nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(errors.New("发送失败"))
+				return svc, repo
+			},
+			want: true,
+		},
+		{
+			name: "部分号码发送失败，只重试失败的号码",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				batch := as
+				batch.Numbers = []string{"13812345678", "13900000000"}
+				retry := as
+				retry.Numbers = []string{"13900000000"}
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any(), time.Second).Return(batch, nil)
+				repo.EXPECT().ReportRetry(gomock.Any(), retry, gomock.Any()).Return(nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}},
+					"13812345678", "13900000000").
+					Return(&sms.PartialError{Numbers: []string{"13900000000"}, Err: errors.New("发送失败")})
+				return svc, repo
+			},
+			want: true,
+		},
+		{
+			name: "重试次数用完，标记失败",
+			mock: func(ctrl *gomock.Controller) (*smsmocks.MockService, *repomocks.MockAsyncSmsRepository) {
+				last := as
+				last.RetryCnt = 3
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any(), time.Second).Return(last, nil)
+				repo.EXPECT().ReportFailed(gomock.Any(), last).Return(nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(errors.New("发送失败"))
+				return svc, repo
+			},
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc, repo := tc.mock(ctrl)
+			s := NewService(svc, repo, testOpts)
+			assert.Equal(t, tc.want, s.sendOne())
+		})
+	}
+}
+
+func TestService_backoff(t *testing.T) {
+	s := NewService(nil, nil, testOpts)
+	assert.Equal(t, time.Second, s.backoff(1))
+	assert.Equal(t, 2*time.Second, s.backoff(2))
+	assert.Equal(t, 4*time.Second, s.backoff(3))
+	assert.Equal(t, 4*time.Second, s.backoff(10))
+}
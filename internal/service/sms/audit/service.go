@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/internal/service/sms"
+)
+
+// 写入发送记录的超时时间
+const recordTimeout = time.Second
+
+// Service 记录每个服务商的每一次发送，包括故障转移与异步重试
+type Service struct {
+	svc      sms.Service
+	provider string
+	repo     repository.SmsLogRepository
+}
+
+func NewService(svc sms.Service, provider string, repo repository.SmsLogRepository) sms.Service {
+	return &Service{
+		svc:      svc,
+		provider: provider,
+		repo:     repo,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	start := time.Now()
+	err := s.svc.Send(ctx, tpl, args, numbers...)
+	latency := time.Since(start)
+
+	// 部分号码失败时，其他号码按照发送成功记录
+	var failed map[string]bool
+	var pe *sms.PartialError
+	if errors.As(err, &pe) {
+		failed = make(map[string]bool, len(pe.Numbers))
+		for _, number := range pe.Numbers {
+			failed[number] = true
+		}
+	}
+
+	logs := make([]domain.SmsLog, 0, len(numbers))
+	for _, number := range numbers {
+		status, message := domain.SmsStatusSuccess, "OK"
+		if err != nil && (failed == nil || failed[number]) {
+			status, message = domain.SmsStatusFailed, err.Error()
+		}
+		logs = append(logs, domain.SmsLog{
+			Biz:      sms.BizFromContext(ctx),
+			Phone:    number,
+			Tpl:      tpl,
+			Provider: s.provider,
+			Status:   status,
+			Message:  message,
+			Latency:  latency,
+		})
+	}
+
+	// 发送超时之后也要记录下来
+	rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if er := s.repo.Add(rctx, logs...); er != nil {
+		// 记录失败不影响发送结果
+		log.Println("记录短信发送记录失败......", er)
+	}
+	return err
+}
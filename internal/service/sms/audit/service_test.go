@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mock"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Send(t *testing.T) {
+	args := []sms.NamedArg{{Name: "code", Val: "123456"}}
+	partialErr := &sms.PartialError{Numbers: []string{"13900000000"}, Err: errors.New("号码格式错误")}
+
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) (sms.Service, repository.SmsLogRepository)
+		wantErr error
+		// 两个号码各自的发送状态
+		wantStatus []domain.SmsStatus
+		wantMsg    []string
+	}{
+		{
+			name: "发送成功",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.SmsLogRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", args, "13812345678", "13900000000").
+					Return(nil)
+				return svc, repomocks.NewMockSmsLogRepository(ctrl)
+			},
+			wantStatus: []domain.SmsStatus{domain.SmsStatusSuccess, domain.SmsStatusSuccess},
+			wantMsg:    []string{"OK", "OK"},
+		},
+		{
+			name: "发送失败",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.SmsLogRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", args, "13812345678", "13900000000").
+					Return(errors.New("余额不足"))
+				return svc, repomocks.NewMockSmsLogRepository(ctrl)
+			},
+			wantErr:    errors.New("余额不足"),
+			wantStatus: []domain.SmsStatus{domain.SmsStatusFailed, domain.SmsStatusFailed},
+			wantMsg:    []string{"余额不足", "余额不足"},
+		},
+		{
+			name: "部分号码发送失败",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.SmsLogRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", args, "13812345678", "13900000000").
+					Return(partialErr)
+				return svc, repomocks.NewMockSmsLogRepository(ctrl)
+			},
+			wantErr:    partialErr,
+			wantStatus: []domain.SmsStatus{domain.SmsStatusSuccess, domain.SmsStatusFailed},
+			wantMsg:    []string{"OK", partialErr.Error()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc, repo := tc.mock(ctrl)
+			var logs []domain.SmsLog
+			repo.(*repomocks.MockSmsLogRepository).EXPECT().Add(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, ls ...domain.SmsLog) error {
+					logs = ls
+					return nil
+				})
+
+			ctx := sms.WithBiz(context.Background(), "login")
+			err := NewService(svc, "tencent", repo).
+				Send(ctx, "login_code", args, "13812345678", "13900000000")
+			assert.Equal(t, tc.wantErr, err)
+
+			assert.Len(t, logs, 2)
+			for i, phone := range []string{"13812345678", "13900000000"} {
+				assert.Equal(t, "login", logs[i].Biz)
+				assert.Equal(t, phone, logs[i].Phone)
+				assert.Equal(t, "login_code", logs[i].Tpl)
+				assert.Equal(t, "tencent", logs[i].Provider)
+				assert.Equal(t, tc.wantStatus[i], logs[i].Status)
+				assert.Equal(t, tc.wantMsg[i], logs[i].Message)
+			}
+		})
+	}
+}
+
+func TestService_Send_RecordFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := smsmocks.NewMockService(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "login_code", gomock.Any(), "13812345678").Return(nil)
+	repo := repomocks.NewMockSmsLogRepository(ctrl)
+	repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+
+	// 记录失败不影响发送结果
+	err := NewService(svc, "tencent", repo).Send(context.Background(), "login_code", nil, "13812345678")
+	assert.NoError(t, err)
+}
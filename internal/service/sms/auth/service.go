@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"webook/internal/service/sms"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnauthorized = errors.New("短信调用方认证失败")
+
+// Claims 调用方 token 中携带的信息
+type Claims struct {
+	jwt.RegisteredClaims
+	// 真正使用的短信模板
+	Tpl string `json:"tpl"`
+	// 调用方的业务
+	Biz string `json:"biz"`
+}
+
+// Service 给内部其他业务方使用的短信服务，调用方把使用共享密钥签名的 token
+// 作为 tpl 传进来，通过校验之后才使用 token 中的模板发送短信
+type Service struct {
+	svc sms.Service
+	key []byte
+	// 小写的业务名称 → 允许使用的模板
+	bizTpls map[string][]string
+}
+
+func NewService(svc sms.Service, key []byte, bizTpls map[string][]string) sms.Service {
+	// 业务名称不区分大小写，viper 读取配置时也会把 key 转成小写
+	lower := make(map[string][]string, len(bizTpls))
+	for biz, tpls := range bizTpls {
+		lower[strings.ToLower(biz)] = tpls
+	}
+	return &Service{
+		svc:     svc,
+		key:     key,
+		bizTpls: lower,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tplToken string, args []sms.NamedArg, numbers ...string) error {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tplToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		return s.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodHS512.Alg()}),
+		// 没有过期时间的 token 泄露之后可以一直使用
+		jwt.WithExpirationRequired())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	// 发送记录中统一使用小写的业务名称
+	biz := strings.ToLower(claims.Biz)
+	tpls, ok := s.bizTpls[biz]
+	if !ok {
+		return fmt.Errorf("%w: 未知的业务 %q", ErrUnauthorized, claims.Biz)
+	}
+
+	if !slices.Contains(tpls, claims.Tpl) {
+		return fmt.Errorf("%w: 业务 %s 不能使用模板 %s", ErrUnauthorized, claims.Biz, claims.Tpl)
+	}
+	return s.svc.Send(sms.WithBiz(ctx, biz), claims.Tpl, args, numbers...)
+}
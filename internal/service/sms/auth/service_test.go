@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mock"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testKey = []byte("test-sms-auth-key-0123456789abcdef")
+
+func TestService_Send(t *testing.T) {
+	args := []sms.NamedArg{{Name: "code", Val: "123456"}}
+
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) sms.Service
+		token   func(t *testing.T) string
+		wantErr error
+	}{
+		{
+			name: "认证通过",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", args, "13812345678").
+					Return(nil)
+				return svc
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, testKey, validClaims("login_code", "user"))
+			},
+		},
+		{
+			name: "业务名称不区分大小写",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "notice", args, "13812345678").
+					Return(nil)
+				return svc
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, testKey, validClaims("notice", "Marketing"))
+			},
+		},
+		{
+			name: "不是 token",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				return smsmocks.NewMockService(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return "login_code"
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "密钥不对",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				return smsmocks.NewMockService(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, []byte("wrong key"), validClaims("login_code", "user"))
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "token 过期",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				return smsmocks.NewMockService(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, testKey, Claims{
+					RegisteredClaims: jwt.RegisteredClaims{
+						ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+					},
+					Tpl: "login_code",
+					Biz: "user",
+				})
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "没有过期时间",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				return smsmocks.NewMockService(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, testKey, Claims{Tpl: "login_code", Biz: "user"})
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "未知的业务",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				return smsmocks.NewMockService(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, testKey, validClaims("login_code", "unknown"))
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "业务不能使用这个模板",
+			mock: func(ctrl *gomock.Controller) sms.Service {
+				return smsmocks.NewMockService(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, testKey, validClaims("notice", "user"))
+			},
+			wantErr: ErrUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewService(tc.mock(ctrl), testKey, map[string][]string{
+				"user":      {"login_code"},
+				"MARKETING": {"notice"},
+			})
+			err := svc.Send(context.Background(), tc.token(t), args, "13812345678")
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestSigner_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	args := []sms.NamedArg{{Name: "code", Val: "123456"}}
+	svc := smsmocks.NewMockService(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "login_code", args, "13812345678").
+		DoAndReturn(func(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+			assert.Equal(t, "login", sms.BizFromContext(ctx))
+			return nil
+		})
+
+	// 进程内的调用方同样受到白名单的约束
+	s := NewSigner(NewService(svc, testKey, map[string][]string{
+		"login": {"login_code"},
+	}), testKey)
+	ctx := sms.WithBiz(context.Background(), "login")
+	assert.NoError(t, s.Send(ctx, "login_code", args, "13812345678"))
+	assert.ErrorIs(t, s.Send(ctx, "notice", args, "13812345678"), ErrUnauthorized)
+	assert.ErrorIs(t, s.Send(context.Background(), "login_code", args, "13812345678"), ErrUnauthorized)
+}
+
+func validClaims(tpl, biz string) Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+		Tpl: tpl,
+		Biz: biz,
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key []byte, claims Claims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
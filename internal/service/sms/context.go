@@ -0,0 +1,16 @@
+package sms
+
+import "context"
+
+type bizKey struct{}
+
+// WithBiz 在 ctx 中记录发送短信的业务，用于审计
+func WithBiz(ctx context.Context, biz string) context.Context {
+	return context.WithValue(ctx, bizKey{}, biz)
+}
+
+// BizFromContext 发送短信的业务，没有设置时返回空字符串
+func BizFromContext(ctx context.Context) string {
+	biz, _ := ctx.Value(bizKey{}).(string)
+	return biz
+}
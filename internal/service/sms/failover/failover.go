@@ -0,0 +1,45 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"webook/internal/service/sms"
+)
+
+var ErrNoService = errors.New("没有可用的短信服务商")
+
+// FailoverService 按照顺序依次尝试每个服务商，直到有一个发送成功
+type FailoverService struct {
+	svcs []sms.Service
+}
+
+func NewFailoverService(svcs []sms.Service) *FailoverService {
+	return &FailoverService{svcs: svcs}
+}
+
+func (f *FailoverService) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	if len(f.svcs) == 0 {
+		return ErrNoService
+	}
+
+	var errs []error
+	for _, svc := range f.svcs {
+		err := svc.Send(ctx, tpl, args, numbers...)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, err)
+		// 发送成功的号码不能再发一次，下一个服务商只发送失败的号码
+		var pe *sms.PartialError
+		if errors.As(err, &pe) && len(pe.Numbers) > 0 {
+			numbers = pe.Numbers
+		}
+		// 调用方已经放弃了，没有必要再尝试下一个
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,191 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFailoverService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) []sms.Service
+		wantErr bool
+	}{
+		{
+			name: "第一个服务商发送成功",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(nil)
+				svc1 := smsmocks.NewMockService(ctrl)
+				return []sms.Service{svc0, svc1}
+			},
+		},
+		{
+			name: "第一个失败，切换到第二个",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(errors.New("发送失败"))
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+		},
+		{
+			name: "全部失败",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(errors.New("发送失败"))
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(errors.New("发送失败"))
+				return []sms.Service{svc0, svc1}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewFailoverService(tc.mock(ctrl))
+			err := svc.Send(context.Background(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678")
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestFailoverService_Send_Partial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	args := []sms.NamedArg{{Name: "code", Val: "123456"}}
+	svc0 := smsmocks.NewMockService(ctrl)
+	svc0.EXPECT().Send(gomock.Any(), "login_code", args, "13812345678", "13900000000").
+		Return(&sms.PartialError{Numbers: []string{"13900000000"}, Err: errors.New("发送失败")})
+	// 已经发送成功的号码不会再收到一次短信
+	svc1 := smsmocks.NewMockService(ctrl)
+	svc1.EXPECT().Send(gomock.Any(), "login_code", args, "13900000000").
+		Return(nil)
+
+	err := NewFailoverService([]sms.Service{svc0, svc1}).
+		Send(context.Background(), "login_code", args, "13812345678", "13900000000")
+	assert.NoError(t, err)
+}
+
+func TestTimeoutFailoverService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock      func(ctrl *gomock.Controller) []sms.Service
+		threshold int32
+		idx       int32
+		cnt       int32
+
+		wantErr error
+		wantIdx int32
+		wantCnt int32
+	}{
+		{
+			name: "没有超过阈值，发送成功重置计数",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				svc1 := smsmocks.NewMockService(ctrl)
+				return []sms.Service{svc0, svc1}
+			},
+			threshold: 3,
+			cnt:       2,
+			wantIdx:   0,
+			wantCnt:   0,
+		},
+		{
+			name: "超时，计数加一",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(context.DeadlineExceeded)
+				svc1 := smsmocks.NewMockService(ctrl)
+				return []sms.Service{svc0, svc1}
+			},
+			threshold: 3,
+			cnt:       2,
+			wantErr:   context.DeadlineExceeded,
+			wantIdx:   0,
+			wantCnt:   3,
+		},
+		{
+			name: "超过阈值，切换服务商",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+			threshold: 3,
+			cnt:       4,
+			wantIdx:   1,
+			wantCnt:   0,
+		},
+		{
+			name: "最后一个服务商超过阈值，切换回第一个",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(context.DeadlineExceeded)
+				svc1 := smsmocks.NewMockService(ctrl)
+				return []sms.Service{svc0, svc1}
+			},
+			threshold: 3,
+			idx:       1,
+			cnt:       4,
+			wantErr:   context.DeadlineExceeded,
+			wantIdx:   0,
+			wantCnt:   1,
+		},
+		{
+			name: "非超时错误，不影响计数",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("模板不存在"))
+				svc1 := smsmocks.NewMockService(ctrl)
+				return []sms.Service{svc0, svc1}
+			},
+			threshold: 3,
+			cnt:       2,
+			wantErr:   errors.New("模板不存在"),
+			wantIdx:   0,
+			wantCnt:   2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewTimeoutFailoverService(tc.mock(ctrl), tc.threshold)
+			svc.idx = tc.idx
+			svc.cnt = tc.cnt
+			err := svc.Send(context.Background(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIdx, svc.idx)
+			assert.Equal(t, tc.wantCnt, svc.cnt)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"webook/internal/service/sms"
+)
+
+// TimeoutFailoverService 连续超时次数超过阈值之后切换到下一个服务商，
+// 超时通常意味着服务商出现了问题，而其他错误多半是请求本身的问题
+type TimeoutFailoverService struct {
+	svcs []sms.Service
+	// 当前使用的服务商
+	idx int32
+	// 当前服务商连续超时的次数
+	cnt int32
+	// 连续超时超过这个次数就切换服务商
+	threshold int32
+}
+
+func NewTimeoutFailoverService(svcs []sms.Service, threshold int32) *TimeoutFailoverService {
+	return &TimeoutFailoverService{
+		svcs:      svcs,
+		threshold: threshold,
+	}
+}
+
+func (t *TimeoutFailoverService) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	if len(t.svcs) == 0 {
+		return ErrNoService
+	}
+
+	idx := atomic.LoadInt32(&t.idx)
+	cnt := atomic.LoadInt32(&t.cnt)
+	if cnt > t.threshold {
+		newIdx := (idx + 1) % int32(len(t.svcs))
+		// 并发时只有一个请求能切换成功，其他请求直接使用切换之后的服务商
+		if atomic.CompareAndSwapInt32(&t.idx, idx, newIdx) {
+			atomic.StoreInt32(&t.cnt, 0)
+		}
+		idx = atomic.LoadInt32(&t.idx)
+	}
+
+	err := t.svcs[idx].Send(ctx, tpl, args, numbers...)
+	switch {
+	case err == nil:
+		// 连续超时被打断
+		atomic.StoreInt32(&t.cnt, 0)
+	case isTimeout(err):
+		atomic.AddInt32(&t.cnt, 1)
+	}
+
+	return err
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
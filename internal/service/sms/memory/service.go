@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"webook/internal/service/sms"
 )
 
 type Service struct {
@@ -12,7 +13,7 @@ func NewService() *Service {
 	return &Service{}
 }
 
-func (s *Service) Send(ctx context.Context, tpl string, args []string, numbers ...string) error {
-	fmt.Println(args)
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	fmt.Println(tpl, args)
 	return nil
 }
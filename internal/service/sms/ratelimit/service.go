@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"webook/internal/service/sms"
+	"webook/pkg/limiter"
+)
+
+var ErrLimited = errors.New("短信发送触发限流")
+
+// Service 限制所有实例发送短信的总速率，避免超出服务商的配额
+type Service struct {
+	svc     sms.Service
+	limiter limiter.Limiter
+	key     string
+}
+
+func NewService(svc sms.Service, l limiter.Limiter) sms.Service {
+	return &Service{
+		svc:     svc,
+		limiter: l,
+		key:     "sms-limiter",
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	limited, err := s.limiter.Limit(ctx, s.key)
+	if err != nil {
+		// Redis 出问题时保守一点，不发送，避免短信被刷
+		return fmt.Errorf("短信限流判断失败: %w", err)
+	}
+	if limited {
+		return ErrLimited
+	}
+	return s.svc.Send(ctx, tpl, args, numbers...)
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mock"
+	"webook/pkg/limiter"
+	limitermocks "webook/pkg/limiter/mock"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) (sms.Service, limiter.Limiter)
+		wantErr error
+	}{
+		{
+			name: "没有触发限流",
+			mock: func(ctrl *gomock.Controller) (sms.Service, limiter.Limiter) {
+				l := limitermocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "sms-limiter").Return(false, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678").
+					Return(nil)
+				return svc, l
+			},
+		},
+		{
+			name: "触发限流",
+			mock: func(ctrl *gomock.Controller) (sms.Service, limiter.Limiter) {
+				l := limitermocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "sms-limiter").Return(true, nil)
+				return smsmocks.NewMockService(ctrl), l
+			},
+			wantErr: ErrLimited,
+		},
+		{
+			name: "限流器出错",
+			mock: func(ctrl *gomock.Controller) (sms.Service, limiter.Limiter) {
+				l := limitermocks.NewMockLimiter(ctrl)
+				l.EXPECT().Limit(gomock.Any(), "sms-limiter").Return(false, errors.New("redis 错误"))
+				return smsmocks.NewMockService(ctrl), l
+			},
+			wantErr: errors.New("短信限流判断失败: redis 错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewService(tc.mock(ctrl))
+			err := svc.Send(context.Background(), "login_code", []sms.NamedArg{{Name: "code", Val: "123456"}}, "13812345678")
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr.Error())
+		})
+	}
+}
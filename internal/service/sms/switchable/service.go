@@ -0,0 +1,27 @@
+package switchable
+
+import (
+	"context"
+	"sync/atomic"
+	"webook/internal/service/sms"
+)
+
+// Service 可以在运行时切换实际使用的短信服务商
+type Service struct {
+	svc atomic.Pointer[sms.Service]
+}
+
+func NewService(svc sms.Service) *Service {
+	s := &Service{}
+	s.Switch(svc)
+	return s
+}
+
+// 切换之后新的请求使用 svc，正在发送的请求不受影响
+func (s *Service) Switch(svc sms.Service) {
+	s.svc.Store(&svc)
+}
+
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	return (*s.svc.Load()).Send(ctx, tpl, args, numbers...)
+}
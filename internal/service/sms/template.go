@@ -0,0 +1,100 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("短信模板不存在")
+	ErrInvalidArgs      = errors.New("短信模板参数不正确")
+)
+
+// Template 业务使用的短信模板，同一个模板在不同服务商有不同的模板 id
+type Template struct {
+	// 逻辑名称，例如 login_code
+	Name string
+	// 参数名，腾讯云按照这个顺序传递参数，阿里云按照参数名传递参数
+	Params []string
+	// 服务商 → 服务商的模板 id
+	ProviderIds map[string]string
+}
+
+// Validate 每个参数都必须提供且不能为空，也不能有多余的参数
+func (t Template) Validate(args []NamedArg) error {
+	if len(args) != len(t.Params) {
+		return fmt.Errorf("%w: 模板 %s 需要 %d 个参数，实际 %d 个",
+			ErrInvalidArgs, t.Name, len(t.Params), len(args))
+	}
+
+	vals := t.Named(args)
+	for _, name := range t.Params {
+		if vals[name] == "" {
+			return fmt.Errorf("%w: 模板 %s 缺少参数 %s", ErrInvalidArgs, t.Name, name)
+		}
+	}
+	return nil
+}
+
+// ProviderId 模板在服务商 provider 的模板 id
+func (t Template) ProviderId(provider string) (string, error) {
+	id, ok := t.ProviderIds[provider]
+	if !ok || id == "" {
+		return "", fmt.Errorf("%w: 模板 %s 没有配置 %s 的模板 id", ErrTemplateNotFound, t.Name, provider)
+	}
+	return id, nil
+}
+
+// Positional 按照 Params 的顺序返回参数值
+func (t Template) Positional(args []NamedArg) []string {
+	vals := t.Named(args)
+	res := make([]string, 0, len(t.Params))
+	for _, name := range t.Params {
+		res = append(res, vals[name])
+	}
+	return res
+}
+
+// Named 参数名 → 参数值
+func (t Template) Named(args []NamedArg) map[string]string {
+	vals := make(map[string]string, len(args))
+	for _, arg := range args {
+		vals[arg.Name] = arg.Val
+	}
+	return vals
+}
+
+// Templates 短信模板注册表，可以在运行时整体替换
+type Templates struct {
+	mu   sync.RWMutex
+	tpls map[string]Template
+}
+
+func NewTemplates(tpls ...Template) *Templates {
+	t := &Templates{}
+	t.Reset(tpls...)
+	return t
+}
+
+// Reset 使用 tpls 替换所有的模板
+func (t *Templates) Reset(tpls ...Template) {
+	m := make(map[string]Template, len(tpls))
+	for _, tpl := range tpls {
+		m[tpl.Name] = tpl
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tpls = m
+}
+
+func (t *Templates) Find(name string) (Template, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	tpl, ok := t.tpls[name]
+	if !ok {
+		return Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return tpl, nil
+}
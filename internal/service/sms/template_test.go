@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Validate(t *testing.T) {
+	tpl := Template{
+		Name:   "login_code",
+		Params: []string{"code", "minutes"},
+	}
+
+	testCases := []struct {
+		name string
+
+		args    []NamedArg
+		wantErr error
+	}{
+		{
+			name: "参数正确，顺序无关",
+			args: []NamedArg{{Name: "minutes", Val: "5"}, {Name: "code", Val: "123456"}},
+		},
+		{
+			name:    "缺少参数",
+			args:    []NamedArg{{Name: "code", Val: "123456"}},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "参数名不对",
+			args:    []NamedArg{{Name: "code", Val: "123456"}, {Name: "min", Val: "5"}},
+			wantErr: ErrInvalidArgs,
+		},
+		{
+			name:    "参数为空",
+			args:    []NamedArg{{Name: "code", Val: ""}, {Name: "minutes", Val: "5"}},
+			wantErr: ErrInvalidArgs,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, tpl.Validate(tc.args), tc.wantErr)
+		})
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	tpls := NewTemplates(Template{
+		Name:        "login_code",
+		Params:      []string{"code", "minutes"},
+		ProviderIds: map[string]string{"tencent": "1877556"},
+	})
+
+	tpl, err := tpls.Find("login_code")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"123456", "5"},
+		tpl.Positional([]NamedArg{{Name: "minutes", Val: "5"}, {Name: "code", Val: "123456"}}))
+
+	id, err := tpl.ProviderId("tencent")
+	require.NoError(t, err)
+	assert.Equal(t, "1877556", id)
+	_, err = tpl.ProviderId("aliyun")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	tpls.Reset()
+	_, err = tpls.Find("login_code")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
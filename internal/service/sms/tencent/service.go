@@ -2,46 +2,81 @@ package tencent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"webook/internal/service/sms"
+
 	"github.com/ecodeclub/ekit"
 	"github.com/ecodeclub/ekit/slice"
-	sms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	tencentsms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
 )
 
+// 腾讯云在 Templates 中的服务商名称
+const provider = "tencent"
+
 type Service struct {
-	appId    *string
-	signName *string
-	client   *sms.Client
+	appId     *string
+	signName  *string
+	client    *tencentsms.Client
+	templates *sms.Templates
 }
 
-func NewService(appId string, signName string, client *sms.Client) *Service {
+func NewService(appId string, signName string, client *tencentsms.Client, templates *sms.Templates) *Service {
 	return &Service{
-		appId:    ekit.ToPtr[string](appId), // convert string to *string
-		signName: ekit.ToPtr[string](signName),
-		client:   client,
+		appId:     ekit.ToPtr[string](appId), // convert string to *string
+		signName:  ekit.ToPtr[string](signName),
+		client:    client,
+		templates: templates,
 	}
 }
 
-func (s *Service) Send(ctx context.Context, tplID string, args []string, numbers ...string) error {
-	req := sms.NewSendSmsRequest()
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	t, err := s.templates.Find(tpl)
+	if err != nil {
+		return err
+	}
+
+	tplID, err := t.ProviderId(provider)
+	if err != nil {
+		return err
+	}
+
+	req := tencentsms.NewSendSmsRequest()
 	req.SmsSdkAppId = s.appId
 	req.SignName = s.signName
 	req.TemplateId = ekit.ToPtr[string](tplID)
 	req.PhoneNumberSet = s.toStringPtrSlice(numbers)
-	req.TemplateParamSet = s.toStringPtrSlice(args)
-	resp, err := s.client.SendSms(req)
+	// 腾讯云的模板参数是按照位置传递的
+	req.TemplateParamSet = s.toStringPtrSlice(t.Positional(args))
+	resp, err := s.client.SendSmsWithContext(ctx, req)
 	if err != nil {
 		return err
 	}
 
-	for _, status := range resp.Response.SendStatusSet {
-		if status.Code == nil || *(status.Code) != "Ok" {
-			return fmt.Errorf("发送短信失败 %s, %s ", *status.Code, *status.Message)
+	// SendStatusSet 与 PhoneNumberSet 的顺序一致，返回的号码带有国家码，直接按照下标对应
+	var failed []string
+	var errs []error
+	for i, status := range resp.Response.SendStatusSet {
+		if status.Code != nil && *status.Code == "Ok" {
+			continue
+		}
+
+		if i < len(numbers) {
+			failed = append(failed, numbers[i])
 		}
+		errs = append(errs, fmt.Errorf("发送短信失败 %s, %s", deref(status.Code), deref(status.Message)))
+	}
+
+	if len(errs) == 0 {
+		return nil
 	}
 
-	return nil
+	if len(resp.Response.SendStatusSet) != len(numbers) {
+		// 对应不上时认为全部失败
+		failed = numbers
+	}
+	return &sms.PartialError{Numbers: failed, Err: errors.Join(errs...)}
 }
 
 func (s *Service) toStringPtrSlice(src []string) []*string {
@@ -49,3 +84,10 @@ func (s *Service) toStringPtrSlice(src []string) []*string {
 		return &src
 	})
 }
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -1,13 +1,33 @@
 package sms
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 type Service interface {
-	Send(ctx context.Context, tplID string, args []string, numbers ...string) error
-	//  Send1(ctx context.Context, tplID string, args []NamedArgs, numbers ...string) error
+	// Send 使用模板 tpl 发送短信，tpl 是模板的逻辑名称，例如 login_code，
+	// 由各个服务商在 Templates 中查找自己的模板 id 并转换参数格式
+	Send(ctx context.Context, tpl string, args []NamedArg, numbers ...string) error
 }
 
-//type NamedArgs struct {
-//	Val  string
-//	Name string
-//}
+// NamedArg 具名的模板参数
+type NamedArg struct {
+	Name string
+	Val  string
+}
+
+// PartialError 批量发送时只有部分号码失败，Numbers 是失败的号码，
+// 重试或者故障转移时只需要重新发送这些号码
+type PartialError struct {
+	Numbers []string
+	Err     error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d 个号码发送失败: %s", len(e.Numbers), e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,31 @@
+package validator
+
+import (
+	"context"
+	"webook/internal/service/sms"
+)
+
+// Service 发送之前校验模板与参数，放在异步发送之前，参数不正确的短信不会进入重试队列
+type Service struct {
+	svc       sms.Service
+	templates *sms.Templates
+}
+
+func NewService(svc sms.Service, templates *sms.Templates) sms.Service {
+	return &Service{
+		svc:       svc,
+		templates: templates,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tpl string, args []sms.NamedArg, numbers ...string) error {
+	t, err := s.templates.Find(tpl)
+	if err != nil {
+		return err
+	}
+
+	if err = t.Validate(args); err != nil {
+		return err
+	}
+	return s.svc.Send(ctx, tpl, args, numbers...)
+}
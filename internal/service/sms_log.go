@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+// SmsLogService 查询短信发送记录与异步短信的积压情况，给客服排查问题使用
+type SmsLogService interface {
+	Find(ctx context.Context, q domain.SmsLogQuery) ([]domain.SmsLog, error)
+	// AsyncStats 各个状态的异步短信数量
+	AsyncStats(ctx context.Context) (domain.AsyncSmsStats, error)
+}
+
+type smsLogService struct {
+	repo      repository.SmsLogRepository
+	asyncRepo repository.AsyncSmsRepository
+}
+
+func NewSmsLogService(repo repository.SmsLogRepository, asyncRepo repository.AsyncSmsRepository) SmsLogService {
+	return &smsLogService{
+		repo:      repo,
+		asyncRepo: asyncRepo,
+	}
+}
+
+func (svc *smsLogService) Find(ctx context.Context, q domain.SmsLogQuery) ([]domain.SmsLog, error) {
+	return svc.repo.Find(ctx, q)
+}
+
+func (svc *smsLogService) AsyncStats(ctx context.Context) (domain.AsyncSmsStats, error) {
+	return svc.asyncRepo.Stats(ctx)
+}
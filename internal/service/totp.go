@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+	"webook/internal/repository"
+	"webook/pkg/totp"
+)
+
+// 验证器中显示的服务名
+const totpIssuer = "webook"
+
+// 恢复码的数量与格式：XXXXX-XXXXX，去掉了容易混淆的字符
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLen      = 10
+	recoveryCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var (
+	ErrTotpEnabled     = repository.ErrTotpEnabled
+	ErrTotpNotSetup    = errors.New("还没有生成两步验证密钥")
+	ErrTotpNotEnabled  = errors.New("没有启用两步验证")
+	ErrTotpInvalidCode = errors.New("两步验证码错误")
+)
+
+// TotpService 基于 TOTP 的两步验证
+type TotpService interface {
+	// Setup 生成新的密钥，返回密钥与验证器扫码使用的 otpauth:// 地址，Enable 之后才会生效
+	Setup(ctx context.Context, uid int64) (secret string, uri string, err error)
+	// Enable 校验验证器生成的验证码，启用两步验证并返回恢复码，恢复码只会返回这一次
+	Enable(ctx context.Context, uid int64, code string) ([]string, error)
+	Enabled(ctx context.Context, uid int64) (bool, error)
+	// Verify 校验验证码或者恢复码，每个都只能使用一次
+	Verify(ctx context.Context, uid int64, code string) error
+}
+
+type totpService struct {
+	repo     repository.TotpRepository
+	userRepo repository.UserRepository
+	now      func() time.Time
+}
+
+func NewTotpService(repo repository.TotpRepository, userRepo repository.UserRepository) TotpService {
+	return &totpService{repo: repo, userRepo: userRepo, now: time.Now}
+}
+
+func (svc *totpService) Setup(ctx context.Context, uid int64) (string, string, error) {
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err = svc.repo.SavePending(ctx, uid, secret); err != nil {
+		return "", "", err
+	}
+
+	// 验证器中用来区分账号，优先使用邮箱
+	account := u.Email
+	if account == "" {
+		account = u.Phone
+	}
+	return secret, totp.URI(totpIssuer, account, secret), nil
+}
+
+func (svc *totpService) Enable(ctx context.Context, uid int64, code string) ([]string, error) {
+	t, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrTotpNotFound {
+		return nil, ErrTotpNotSetup
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Enabled {
+		return nil, ErrTotpEnabled
+	}
+
+	step, ok := totp.Validate(t.Secret, code, svc.now())
+	if !ok {
+		return nil, ErrTotpInvalidCode
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		c, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+	}
+
+	// 保存的是去掉分隔符之后的恢复码
+	normalized := make([]string, 0, len(codes))
+	for _, c := range codes {
+		normalized = append(normalized, normalizeRecoveryCode(c))
+	}
+	if err = svc.repo.Enable(ctx, uid, step, normalized); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (svc *totpService) Enabled(ctx context.Context, uid int64) (bool, error) {
+	t, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrTotpNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return t.Enabled, nil
+}
+
+func (svc *totpService) Verify(ctx context.Context, uid int64, code string) error {
+	t, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrTotpNotFound {
+		return ErrTotpNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+
+	if !t.Enabled {
+		return ErrTotpNotEnabled
+	}
+
+	// 6 位数字是验证器生成的验证码，其余的按照恢复码处理
+	if step, ok := totp.Validate(t.Secret, code, svc.now()); ok {
+		err = svc.repo.UseStep(ctx, uid, step)
+		if err == repository.ErrTotpStepUsed {
+			return ErrTotpInvalidCode
+		}
+		return err
+	}
+
+	err = svc.repo.UseRecoveryCode(ctx, uid, normalizeRecoveryCode(code))
+	if err == repository.ErrRecoveryCodeNotFound {
+		return ErrTotpInvalidCode
+	}
+	return err
+}
+
+func generateRecoveryCode() (string, error) {
+	var sb strings.Builder
+	size := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeLen; i++ {
+		if i == recoveryCodeLen/2 {
+			sb.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// 用户输入的恢复码可能带有分隔符、空格或者小写字母
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mock"
+	"webook/pkg/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// base32("12345678901234567890")
+const testTotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpService_Verify(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code, err := totp.Code(testTotpSecret, totp.Step(now))
+	require.NoError(t, err)
+	enabled := domain.Totp{Uid: 123, Secret: testTotpSecret, Enabled: true}
+
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) repository.TotpRepository
+		code    string
+		wantErr error
+	}{
+		{
+			name: "验证码正确",
+			mock: func(ctrl *gomock.Controller) repository.TotpRepository {
+				repo := repomocks.NewMockTotpRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(123), totp.Step(now)).Return(nil)
+				return repo
+			},
+			code: code,
+		},
+		{
+			name: "验证码已经用过",
+			mock: func(ctrl *gomock.Controller) repository.TotpRepository {
+				repo := repomocks.NewMockTotpRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(123), totp.Step(now)).Return(repository.ErrTotpStepUsed)
+				return repo
+			},
+			code:    code,
+			wantErr: ErrTotpInvalidCode,
+		},
+		{
+			name: "恢复码",
+			mock: func(ctrl *gomock.Controller) repository.TotpRepository {
+				repo := repomocks.NewMockTotpRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(123), "ABCDE23456").Return(nil)
+				return repo
+			},
+			code: "abcde-23456",
+		},
+		{
+			name: "恢复码不存在",
+			mock: func(ctrl *gomock.Controller) repository.TotpRepository {
+				repo := repomocks.NewMockTotpRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).Return(enabled, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(123), "ABCDE23456").
+					Return(repository.ErrRecoveryCodeNotFound)
+				return repo
+			},
+			code:    "ABCDE-23456",
+			wantErr: ErrTotpInvalidCode,
+		},
+		{
+			name: "没有启用",
+			mock: func(ctrl *gomock.Controller) repository.TotpRepository {
+				repo := repomocks.NewMockTotpRepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(123)).
+					Return(domain.Totp{Uid: 123, Secret: testTotpSecret}, nil)
+				return repo
+			},
+			code:    code,
+			wantErr: ErrTotpNotEnabled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := &totpService{repo: tc.mock(ctrl), now: func() time.Time { return now }}
+			err := svc.Verify(context.Background(), 123, tc.code)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestTotpService_Enable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1111111109, 0)
+	code, err := totp.Code(testTotpSecret, totp.Step(now))
+	require.NoError(t, err)
+
+	repo := repomocks.NewMockTotpRepository(ctrl)
+	repo.EXPECT().FindByUid(gomock.Any(), int64(123)).
+		Return(domain.Totp{Uid: 123, Secret: testTotpSecret}, nil).Times(2)
+	var saved []string
+	repo.EXPECT().Enable(gomock.Any(), int64(123), totp.Step(now), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid, step int64, codes []string) error {
+			saved = codes
+			return nil
+		})
+
+	svc := &totpService{repo: repo, now: func() time.Time { return now }}
+	codes, err := svc.Enable(context.Background(), 123, code)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	// 返回给用户的带分隔符，保存的是去掉分隔符之后的
+	assert.Regexp(t, `^[A-Z2-9]{5}-[A-Z2-9]{5}$`, codes[0])
+	assert.Equal(t, normalizeRecoveryCode(codes[0]), saved[0])
+
+	_, err = svc.Enable(context.Background(), 123, "000000")
+	assert.Equal(t, ErrTotpInvalidCode, err)
+}
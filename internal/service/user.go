@@ -10,13 +10,27 @@ import (
 )
 
 var ErrUserDuplicateEmail = repository.ErrUserDuplicate
+var ErrUserDuplicatePhone = repository.ErrUserDuplicate
+var ErrUserNotFound = repository.ErrUserNotFound
 var ErrInvalidUserOrPassword = errors.New("账号/密码不对......")
 
 type UserService interface {
 	SignUp(ctx context.Context, u domain.User) error
 	Login(ctx context.Context, u domain.User) (domain.User, error)
 	Profile(ctx context.Context, userId int64) (domain.User, error)
+	FindByEmail(ctx context.Context, email string) (domain.User, error)
 	FindOrCreate(ctx context.Context, phone string) (domain.User, error)
+	FindOrCreateByEmail(ctx context.Context, email string) (domain.User, error)
+	FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error)
+	UpdateNonSensitiveInfo(ctx context.Context, u domain.User) error
+	// ResetPassword 通过手机号或者邮箱找到用户并重置密码，返回被重置的用户
+	ResetPassword(ctx context.Context, u domain.User) (domain.User, error)
+	// ChangePassword 已经登录的用户修改密码，需要校验旧密码
+	ChangePassword(ctx context.Context, uid int64, oldPassword, newPassword string) error
+	// UpdatePhone 绑定或者修改手机号，调用方需要先校验新手机号的验证码
+	UpdatePhone(ctx context.Context, uid int64, phone string) error
+	// UpdateEmail 修改邮箱，调用方需要先校验新邮箱的验证码
+	UpdateEmail(ctx context.Context, uid int64, email string) error
 }
 
 type userService struct {
@@ -60,10 +74,11 @@ func (svc *userService) Login(ctx context.Context, u domain.User) (domain.User,
 }
 
 func (svc *userService) Profile(ctx context.Context, userId int64) (domain.User, error) {
-	// TODO
-	u, err := svc.repo.FindById(ctx, userId)
+	return svc.repo.FindById(ctx, userId)
+}
 
-	return u, err
+func (svc *userService) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	return svc.repo.FindByEmail(ctx, email)
 }
 
 func (svc *userService) FindOrCreate(ctx context.Context, phone string) (domain.User, error) {
@@ -85,3 +100,99 @@ func (svc *userService) FindOrCreate(ctx context.Context, phone string) (domain.
 
 	return svc.repo.FindByPhone(ctx, phone)
 }
+
+// 邮箱验证码登录，用户不存在则创建一个没有密码的用户
+func (svc *userService) FindOrCreateByEmail(ctx context.Context, email string) (domain.User, error) {
+	u, err := svc.repo.FindByEmail(ctx, email)
+	if err != repository.ErrUserNotFound {
+		return u, err
+	}
+
+	err = svc.repo.Create(ctx, domain.User{
+		Email: email,
+	})
+	// 并发创建的时候可能已经被别人创建了
+	if err != nil && err != repository.ErrUserDuplicate {
+		return domain.User{}, err
+	}
+
+	return svc.repo.FindByEmail(ctx, email)
+}
+
+// 微信扫码登录，用户不存在则创建一个只有微信标识的用户
+func (svc *userService) FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error) {
+	u, err := svc.repo.FindByWechat(ctx, info.OpenId)
+	if err != repository.ErrUserNotFound {
+		return u, err
+	}
+
+	err = svc.repo.Create(ctx, domain.User{
+		WechatInfo: info,
+	})
+	// 并发创建的时候可能已经被别人创建了
+	if err != nil && err != repository.ErrUserDuplicate {
+		return domain.User{}, err
+	}
+
+	return svc.repo.FindByWechat(ctx, info.OpenId)
+}
+
+// 更新用户的非敏感信息(昵称、生日、个人简介)
+func (svc *userService) UpdateNonSensitiveInfo(ctx context.Context, u domain.User) error {
+	return svc.repo.Update(ctx, u)
+}
+
+// 调用方需要先校验验证码，确认请求方持有该手机号或者邮箱
+func (svc *userService) ResetPassword(ctx context.Context, u domain.User) (domain.User, error) {
+	var (
+		ur  domain.User
+		err error
+	)
+	if u.Phone != "" {
+		ur, err = svc.repo.FindByPhone(ctx, u.Phone)
+	} else {
+		ur, err = svc.repo.FindByEmail(ctx, u.Email)
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if err = svc.repo.UpdatePassword(ctx, ur.Id, string(hash)); err != nil {
+		return domain.User{}, err
+	}
+
+	return ur, nil
+}
+
+func (svc *userService) ChangePassword(ctx context.Context, uid int64, oldPassword, newPassword string) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	// 与登录一样校验旧密码，通过验证码注册的用户没有密码，只能走找回密码
+	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(oldPassword))
+	if err != nil {
+		return ErrInvalidUserOrPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return svc.repo.UpdatePassword(ctx, uid, string(hash))
+}
+
+func (svc *userService) UpdatePhone(ctx context.Context, uid int64, phone string) error {
+	return svc.repo.UpdatePhone(ctx, uid, phone)
+}
+
+func (svc *userService) UpdateEmail(ctx context.Context, uid int64, email string) error {
+	return svc.repo.UpdateEmail(ctx, uid, email)
+}
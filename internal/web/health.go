@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 单个依赖的检查超时时间，k8s 探针默认 1s 超时
+const healthCheckTimeout = time.Millisecond * 800
+
+// 探测依赖是否可用
+type healthChecker func(ctx context.Context) error
+
+// HealthHandler 提供 k8s 的存活与就绪探针
+type HealthHandler struct {
+	checkers map[string]healthChecker
+}
+
+func NewHealthHandler(db *gorm.DB, cmd redis.Cmdable) *HealthHandler {
+	return &HealthHandler{
+		checkers: map[string]healthChecker{
+			"mysql": func(ctx context.Context) error {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			},
+			"redis": func(ctx context.Context) error {
+				return cmd.Ping(ctx).Err()
+			},
+		},
+	}
+}
+
+func (h *HealthHandler) RegisterRoutes(server *gin.Engine) {
+	hg := server.Group("/health")
+	hg.GET("/live", h.Live)
+	hg.GET("/ready", h.Ready)
+}
+
+// 存活探针：进程能够处理请求即可，不检查依赖，避免依赖故障导致 pod 被反复重启
+func (h *HealthHandler) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "up",
+	})
+}
+
+// 就绪探针：MySQL 与 Redis 都可用时才接收流量
+func (h *HealthHandler) Ready(ctx *gin.Context) {
+	type CheckResult struct {
+		Status  string `json:"status"`
+		Latency string `json:"latency"`
+		Error   string `json:"error,omitempty"`
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]CheckResult, len(h.checkers))
+		ready   = true
+	)
+	for name, check := range h.checkers {
+		wg.Add(1)
+		go func(name string, check healthChecker) {
+			defer wg.Done()
+			c, cancel := context.WithTimeout(ctx.Request.Context(), healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(c)
+			res := CheckResult{
+				Status:  "up",
+				Latency: time.Since(start).String(),
+			}
+			if err != nil {
+				res.Status = "down"
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = res
+			if err != nil {
+				ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	status, code := "up", http.StatusOK
+	if !ready {
+		status, code = "down", http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(code, gin.H{
+		"status": status,
+		"checks": results,
+	})
+}
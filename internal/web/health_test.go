@@ -0,0 +1,80 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	up := func(ctx context.Context) error {
+		return nil
+	}
+	// 模拟依赖卡住，直到超时
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	testCases := []struct {
+		name string
+
+		checkers   map[string]healthChecker
+		wantCode   int
+		wantStatus map[string]string
+	}{
+		{
+			name:       "依赖全部可用",
+			checkers:   map[string]healthChecker{"mysql": up, "redis": up},
+			wantCode:   http.StatusOK,
+			wantStatus: map[string]string{"mysql": "up", "redis": "up"},
+		},
+		{
+			name: "Redis 不可用",
+			checkers: map[string]healthChecker{"mysql": up, "redis": func(ctx context.Context) error {
+				return errors.New("connection refused")
+			}},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"mysql": "up", "redis": "down"},
+		},
+		{
+			name:       "MySQL 超时",
+			checkers:   map[string]healthChecker{"mysql": hang, "redis": up},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"mysql": "down", "redis": "up"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.Default()
+			h := &HealthHandler{checkers: tc.checkers}
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, "/health/ready", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var body struct {
+				Status string `json:"status"`
+				Checks map[string]struct {
+					Status string `json:"status"`
+				} `json:"checks"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			for name, status := range tc.wantStatus {
+				assert.Equal(t, status, body.Checks[name].Status, name)
+			}
+		})
+	}
+}
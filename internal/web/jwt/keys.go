@@ -0,0 +1,201 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"webook/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("token 无效")
+
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// 只用于校验的旧 key 没有签名 key
+	signKey   any
+	verifyKey any
+}
+
+// KeySet 使用当前 key 签名，并根据 token header 中的 kid 选择校验的 key，
+// 从而可以在不让用户重新登录的情况下轮换 key
+type KeySet struct {
+	current *signingKey
+	keys    map[string]*signingKey
+}
+
+func NewKeySet(cfg config.JWTKeySetConfig) (*KeySet, error) {
+	ks := &KeySet{
+		keys: make(map[string]*signingKey, len(cfg.Keys)),
+	}
+
+	for _, kc := range cfg.Keys {
+		if kc.Id == "" {
+			return nil, errors.New("jwt key 的 id 不能为空")
+		}
+
+		if _, ok := ks.keys[kc.Id]; ok {
+			return nil, fmt.Errorf("jwt key %s 重复", kc.Id)
+		}
+
+		k, err := newSigningKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kc.Id, err)
+		}
+
+		ks.keys[kc.Id] = k
+	}
+
+	current, ok := ks.keys[cfg.Current]
+	if !ok {
+		return nil, fmt.Errorf("当前 jwt key %s 不存在", cfg.Current)
+	}
+
+	if current.signKey == nil {
+		return nil, fmt.Errorf("当前 jwt key %s 缺少私钥", cfg.Current)
+	}
+
+	ks.current = current
+	return ks, nil
+}
+
+func newSigningKey(kc config.JWTKeyConfig) (*signingKey, error) {
+	k := &signingKey{
+		id:     kc.Id,
+		method: jwt.GetSigningMethod(kc.Method),
+	}
+
+	switch k.method {
+	case jwt.SigningMethodHS512:
+		secret := []byte(kc.Secret)
+		if kc.SecretFile != "" {
+			b, err := os.ReadFile(kc.SecretFile)
+			if err != nil {
+				return nil, err
+			}
+			// 通过 echo 或者编辑器写入的文件末尾通常带有换行
+			secret = bytes.TrimSpace(b)
+		}
+
+		if len(secret) == 0 {
+			return nil, errors.New("缺少 secret")
+		}
+
+		k.signKey = secret
+		k.verifyKey = k.signKey
+
+	case jwt.SigningMethodRS256:
+		if kc.PrivateKeyFile != "" {
+			pem, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+
+			k.signKey = priv
+			k.verifyKey = &priv.PublicKey
+		}
+
+		if kc.PublicKeyFile != "" {
+			pem, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+
+			k.verifyKey = pub
+		}
+
+	case jwt.SigningMethodEdDSA:
+		if kc.PrivateKeyFile != "" {
+			pem, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+
+			edPriv, ok := priv.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("私钥类型错误 %T", priv)
+			}
+
+			k.signKey = edPriv
+			k.verifyKey = edPriv.Public()
+		}
+
+		if kc.PublicKeyFile != "" {
+			pem, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			pub, err := jwt.ParseEdPublicKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+
+			k.verifyKey = pub
+		}
+
+	default:
+		return nil, fmt.Errorf("不支持的签名算法 %q", kc.Method)
+	}
+
+	if k.verifyKey == nil {
+		return nil, errors.New("缺少公钥或私钥")
+	}
+
+	return k, nil
+}
+
+// 使用当前 key 签名，并在 header 中写入 kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.current.method, claims)
+	token.Header["kid"] = ks.current.id
+	return token.SignedString(ks.current.signKey)
+}
+
+// 根据 kid 找到对应的 key 进行校验，算法必须与 key 配置的一致
+func (ks *KeySet) Parse(tokenStr string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenStr, claims, ks.keyFunc)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if !token.Valid {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("未知的 kid %q", kid)
+	}
+
+	// 防止算法混淆攻击，例如用公钥当作 HMAC 的密钥
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("kid %s 的签名算法不匹配 %s", kid, token.Method.Alg())
+	}
+
+	return k.verifyKey, nil
+}
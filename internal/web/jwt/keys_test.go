@@ -0,0 +1,247 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"webook/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	dir := t.TempDir()
+	rsaPriv, rsaPub := writeRSAKeys(t, dir)
+	edPriv, edPub := writeEdKeys(t, dir)
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret-1\n"), 0600))
+
+	testCases := []struct {
+		name string
+
+		// 签名使用的 key
+		signCfg config.JWTKeySetConfig
+		// 校验使用的 key
+		parseCfg config.JWTKeySetConfig
+		wantErr  bool
+	}{
+		{
+			name: "HS512",
+			signCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+		},
+		{
+			name: "轮换之后旧 key 签发的 token 仍然有效",
+			signCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k2",
+				Keys: []config.JWTKeyConfig{
+					{Id: "k2", Method: "HS512", Secret: "secret-2"},
+					{Id: "k1", Method: "HS512", Secret: "secret-1"},
+				},
+			},
+		},
+		{
+			name: "旧 key 下线之后 token 失效",
+			signCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k2",
+				Keys:    []config.JWTKeyConfig{{Id: "k2", Method: "HS512", Secret: "secret-2"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "kid 相同但是密钥不同",
+			signCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-2"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "RS256，校验方只有公钥",
+			signCfg: config.JWTKeySetConfig{
+				Current: "rsa",
+				Keys:    []config.JWTKeyConfig{{Id: "rsa", Method: "RS256", PrivateKeyFile: rsaPriv}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys: []config.JWTKeyConfig{
+					{Id: "k1", Method: "HS512", Secret: "secret-1"},
+					{Id: "rsa", Method: "RS256", PublicKeyFile: rsaPub},
+				},
+			},
+		},
+		{
+			name: "EdDSA",
+			signCfg: config.JWTKeySetConfig{
+				Current: "ed",
+				Keys:    []config.JWTKeyConfig{{Id: "ed", Method: "EdDSA", PrivateKeyFile: edPriv}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys: []config.JWTKeyConfig{
+					{Id: "k1", Method: "HS512", Secret: "secret-1"},
+					{Id: "ed", Method: "EdDSA", PublicKeyFile: edPub},
+				},
+			},
+		},
+		{
+			name: "HS512 从文件读取密钥",
+			signCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", SecretFile: secretFile}},
+			},
+		},
+		{
+			name: "签名算法与 kid 配置的不一致",
+			signCfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+			parseCfg: config.JWTKeySetConfig{
+				Current: "k2",
+				Keys: []config.JWTKeyConfig{
+					{Id: "k2", Method: "HS512", Secret: "secret-2"},
+					{Id: "k1", Method: "RS256", PublicKeyFile: rsaPub},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signKeys, err := NewKeySet(tc.signCfg)
+			require.NoError(t, err)
+			parseKeys, err := NewKeySet(tc.parseCfg)
+			require.NoError(t, err)
+
+			tokenStr, err := signKeys.Sign(UserClaims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				},
+				Uid:  123,
+				Ssid: "ssid-123",
+			})
+			require.NoError(t, err)
+
+			var claims UserClaims
+			err = parseKeys.Parse(tokenStr, &claims)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(123), claims.Uid)
+			assert.Equal(t, "ssid-123", claims.Ssid)
+		})
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	dir := t.TempDir()
+	_, rsaPub := writeRSAKeys(t, dir)
+
+	testCases := []struct {
+		name string
+		cfg  config.JWTKeySetConfig
+	}{
+		{
+			name: "当前 key 不存在",
+			cfg: config.JWTKeySetConfig{
+				Current: "k2",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+			},
+		},
+		{
+			name: "当前 key 没有私钥",
+			cfg: config.JWTKeySetConfig{
+				Current: "rsa",
+				Keys:    []config.JWTKeyConfig{{Id: "rsa", Method: "RS256", PublicKeyFile: rsaPub}},
+			},
+		},
+		{
+			name: "不支持的算法",
+			cfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "none", Secret: "secret-1"}},
+			},
+		},
+		{
+			name: "kid 重复",
+			cfg: config.JWTKeySetConfig{
+				Current: "k1",
+				Keys: []config.JWTKeyConfig{
+					{Id: "k1", Method: "HS512", Secret: "secret-1"},
+					{Id: "k1", Method: "HS512", Secret: "secret-2"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewKeySet(tc.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func writeRSAKeys(t *testing.T, dir string) (string, string) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	return writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv)),
+		writePEM(t, dir, "rsa.pub.pem", "PUBLIC KEY", pubDER)
+}
+
+func writeEdKeys(t *testing.T, dir string) (string, string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	return writePEM(t, dir, "ed.pem", "PRIVATE KEY", privDER),
+		writePEM(t, dir, "ed.pub.pem", "PUBLIC KEY", pubDER)
+}
+
+func writePEM(t *testing.T, dir, name, typ string, der []byte) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600)
+	require.NoError(t, err)
+	return path
+}
@@ -0,0 +1,15 @@
+-- 退出用户的所有会话
+-- 读取会话列表、标记 ssid、删除会话列表必须是原子的，
+-- 否则中间新登录的会话会从列表中删除，但是没有被标记为退出
+-- users:sessions:123
+local sessionsKey = KEYS[1]
+-- users:ssid:
+local prefix = ARGV[1]
+-- 标记需要保留到 refresh token 过期，秒
+local expiration = tonumber(ARGV[2])
+local ssids = redis.call("smembers", sessionsKey)
+for _, ssid in ipairs(ssids) do
+    redis.call("set", prefix..ssid, "", "EX", expiration)
+end
+redis.call("del", sessionsKey)
+return #ssids
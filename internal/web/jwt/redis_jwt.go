@@ -0,0 +1,197 @@
+package jwt
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// access token 有效期短，泄露之后影响有限
+	atExpiration = time.Minute * 30
+	// refresh token 有效期长，只用于换取新的 access token
+	rtExpiration = time.Hour * 24 * 7
+	// mfa token 只用于完成两步验证
+	mfaExpiration = time.Minute * 5
+)
+
+// 标记已经退出登录的 ssid，例如 users:ssid:xxx
+const ssidKeyPrefix = "users:ssid:"
+
+//go:embed lua/revoke_sessions.lua
+var luaRevokeSessions string
+
+// RedisJWTHandler 使用 redis 记录已经退出登录的 ssid
+type RedisJWTHandler struct {
+	cmd redis.Cmdable
+	// access token 与 refresh token 使用不同的 key
+	atKeys *KeySet
+	rtKeys *KeySet
+}
+
+func NewRedisJWTHandler(cmd redis.Cmdable, atKeys *KeySet, rtKeys *KeySet) Handler {
+	return &RedisJWTHandler{
+		cmd:    cmd,
+		atKeys: atKeys,
+		rtKeys: rtKeys,
+	}
+}
+
+func (h *RedisJWTHandler) SetLoginToken(ctx *gin.Context, uid int64) error {
+	// 每次登录都是一个新的会话，access token 与 refresh token 共享 ssid
+	ssid := uuid.New().String()
+	if err := h.SetJWTToken(ctx, uid, ssid); err != nil {
+		return err
+	}
+
+	// 记录用户的所有会话，重置密码之后需要全部退出登录
+	sessionsKey := h.sessionsKey(uid)
+	_, err := h.cmd.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, sessionsKey, ssid)
+		pipe.Expire(ctx, sessionsKey, rtExpiration)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.setRefreshToken(ctx, uid, ssid)
+}
+
+func (h *RedisJWTHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string) error {
+	claims := UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(atExpiration)),
+		},
+		Uid:       uid,
+		Ssid:      ssid,
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	// 使用JWT设置登录态
+	tokenStr, err := h.atKeys.Sign(claims)
+	if err != nil {
+		return err
+	}
+
+	// 将生成的token写入到响应头
+	ctx.Header("x-jwt-token", tokenStr)
+	return nil
+}
+
+func (h *RedisJWTHandler) setRefreshToken(ctx *gin.Context, uid int64, ssid string) error {
+	claims := RefreshClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(rtExpiration)),
+		},
+		Uid:       uid,
+		Ssid:      ssid,
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	tokenStr, err := h.rtKeys.Sign(claims)
+	if err != nil {
+		return err
+	}
+
+	ctx.Header("x-refresh-token", tokenStr)
+	return nil
+}
+
+func (h *RedisJWTHandler) SetMfaToken(ctx *gin.Context, uid int64, account string) error {
+	claims := MfaClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaExpiration)),
+		},
+		PendingUid: uid,
+		Account:    account,
+		UserAgent:  ctx.Request.UserAgent(),
+	}
+
+	tokenStr, err := h.atKeys.Sign(claims)
+	if err != nil {
+		return err
+	}
+
+	ctx.Header("x-mfa-token", tokenStr)
+	return nil
+}
+
+func (h *RedisJWTHandler) ParseMfaToken(tokenStr string) (*MfaClaims, error) {
+	claims := &MfaClaims{}
+	if err := h.atKeys.Parse(tokenStr, claims); err != nil {
+		return nil, err
+	}
+
+	// access token 与 mfa token 使用同一组 key，access token 中没有 PendingUid
+	if claims.PendingUid == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (h *RedisJWTHandler) ParseAccessToken(tokenStr string) (*UserClaims, error) {
+	claims := &UserClaims{}
+	if err := h.atKeys.Parse(tokenStr, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (h *RedisJWTHandler) ParseRefreshToken(tokenStr string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := h.rtKeys.Parse(tokenStr, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (h *RedisJWTHandler) ClearToken(ctx *gin.Context) error {
+	// 前端拿到空 token 之后会覆盖本地保存的 token
+	ctx.Header("x-jwt-token", "")
+	ctx.Header("x-refresh-token", "")
+
+	c, _ := ctx.Get("claims")
+	claims, ok := c.(*UserClaims)
+	if !ok {
+		return fmt.Errorf("claims 类型错误 %T", c)
+	}
+
+	// refresh token 与 access token 共享 ssid，
+	// 记录保留到 refresh token 自然过期即可
+	return h.cmd.Set(ctx, h.key(claims.Ssid), "", rtExpiration).Err()
+}
+
+func (h *RedisJWTHandler) CheckSession(ctx *gin.Context, ssid string) error {
+	cnt, err := h.cmd.Exists(ctx, h.key(ssid)).Result()
+	if err != nil {
+		return err
+	}
+
+	if cnt > 0 {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+func (h *RedisJWTHandler) RevokeSessions(ctx *gin.Context, uid int64) error {
+	return h.cmd.Eval(ctx, luaRevokeSessions, []string{h.sessionsKey(uid)},
+		ssidKeyPrefix, int64(rtExpiration.Seconds())).Err()
+}
+
+func (h *RedisJWTHandler) sessionsKey(uid int64) string {
+	return fmt.Sprintf("users:sessions:%d", uid)
+}
+
+func (h *RedisJWTHandler) key(ssid string) string {
+	return ssidKeyPrefix + ssid
+}
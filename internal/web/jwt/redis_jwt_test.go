@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mfa token 与 access token 使用同一组 key，不能互相冒用
+func TestRedisJWTHandler_MfaToken(t *testing.T) {
+	atKeys, err := NewKeySet(config.JWTKeySetConfig{
+		Current: "k1",
+		Keys:    []config.JWTKeyConfig{{Id: "k1", Method: "HS512", Secret: "secret-1"}},
+	})
+	require.NoError(t, err)
+	h := NewRedisJWTHandler(nil, atKeys, atKeys)
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/users/login", nil)
+	ctx.Request.Header.Set("User-Agent", "webook-test")
+
+	require.NoError(t, h.SetMfaToken(ctx, 123, "abc@qq.com"))
+	mfaToken := ctx.Writer.Header().Get("x-mfa-token")
+	mc, err := h.ParseMfaToken(mfaToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(123), mc.PendingUid)
+	assert.Equal(t, "abc@qq.com", mc.Account)
+	assert.Equal(t, "webook-test", mc.UserAgent)
+
+	// 登录中间件会拒绝 Uid 为 0 的 access token
+	uc, err := h.ParseAccessToken(mfaToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), uc.Uid)
+
+	require.NoError(t, h.SetJWTToken(ctx, 123, "ssid-123"))
+	_, err = h.ParseMfaToken(ctx.Writer.Header().Get("x-jwt-token"))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
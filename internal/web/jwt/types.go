@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrSessionRevoked = errors.New("session 已经退出登录")
+
+// Handler 负责 JWT 的签发、退出登录与会话校验
+type Handler interface {
+	// 登录成功之后同时设置 access token 与 refresh token
+	SetLoginToken(ctx *gin.Context, uid int64) error
+	SetJWTToken(ctx *gin.Context, uid int64, ssid string) error
+	// 校验签名与过期时间，返回 token 中的数据
+	ParseAccessToken(tokenStr string) (*UserClaims, error)
+	ParseRefreshToken(tokenStr string) (*RefreshClaims, error)
+	// 退出登录，记录当前 ssid 已经失效
+	ClearToken(ctx *gin.Context) error
+	// 校验 ssid 是否已经退出登录
+	CheckSession(ctx *gin.Context, ssid string) error
+	// 让用户所有已经登录的会话失效，例如重置密码之后
+	RevokeSessions(ctx *gin.Context, uid int64) error
+	// 密码校验通过但是还需要两步验证时，设置一个短期的 mfa token
+	SetMfaToken(ctx *gin.Context, uid int64, account string) error
+	ParseMfaToken(tokenStr string) (*MfaClaims, error)
+}
+
+type UserClaims struct {
+	jwt.RegisteredClaims
+	Uid       int64 // 需要放入token中的数据
+	Ssid      string
+	UserAgent string
+}
+
+type RefreshClaims struct {
+	jwt.RegisteredClaims
+	Uid       int64
+	Ssid      string
+	UserAgent string
+}
+
+// MfaClaims 只能用来完成两步验证，
+// uid 的字段名与 UserClaims 不同，不会被当成 access token 使用
+type MfaClaims struct {
+	jwt.RegisteredClaims
+	PendingUid int64
+	// 登录使用的账号，两步验证失败时同样计入登录失败次数
+	Account   string
+	UserAgent string
+}
+
+// 从 Authorization: Bearer xxx 中取出 token
+func ExtractToken(ctx *gin.Context) string {
+	tokenHeader := ctx.GetHeader("Authorization")
+	segs := strings.Split(tokenHeader, " ")
+	if len(segs) != 2 {
+		// tokenHeader格式错误
+		return ""
+	}
+
+	return segs[1]
+}
@@ -1,22 +1,21 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
-	"strings"
-	"time"
-	"webook/internal/web"
+	ijwt "webook/internal/web/jwt"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type LoginJWTMiddlewareBuilder struct {
 	paths []string
+	ijwt.Handler
 }
 
-func NewLoginJWTMiddlewareBuilder() *LoginJWTMiddlewareBuilder {
-	return &LoginJWTMiddlewareBuilder{}
+func NewLoginJWTMiddlewareBuilder(jwtHdl ijwt.Handler) *LoginJWTMiddlewareBuilder {
+	return &LoginJWTMiddlewareBuilder{
+		Handler: jwtHdl,
+	}
 }
 
 func (l *LoginJWTMiddlewareBuilder) IgnorePaths(path string) *LoginJWTMiddlewareBuilder {
@@ -32,34 +31,21 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 			}
 		}
 
-		tokenHeader := ctx.GetHeader("Authorization")
-		if tokenHeader == "" {
-			// 没登录
+		tokenStr := ijwt.ExtractToken(ctx)
+		if tokenStr == "" {
+			// 没登录或者 Authorization 格式错误
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
 		// 解析token
-		segs := strings.Split(tokenHeader, " ")
-		if len(segs) != 2 {
-			// tokenHeader格式错误
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		tokenStr := segs[1]
-		claims := &web.UserClaims{}
-		key := []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvf0")
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return key, nil
-		})
-
+		claims, err := l.ParseAccessToken(tokenStr)
 		if err != nil {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
-		if token == nil || !token.Valid || claims.Uid == 0 {
+		if claims.Uid == 0 {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
@@ -71,20 +57,13 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 			return
 		}
 
-		now := time.Now()
-		// 每10更新一次token
-		if claims.ExpiresAt.Sub(now) < time.Second*50 {
-			claims.ExpiresAt = jwt.NewNumericDate(now.Add(time.Minute))
-			key := []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvf0")
-			tokenStr, err := token.SignedString(key)
-			if err != nil {
-				log.Println("jwt 续约失败......", err)
-			}
-
-			ctx.Header("x-jwt-token", tokenStr)
+		// 已经退出登录的会话
+		if err = l.CheckSession(ctx, claims.Ssid); err != nil {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
 		}
 
-		// token过期
+		// access token 过期之后不再自动续约，由前端通过 refresh token 换取
 		ctx.Set("claims", claims)
 	}
 }
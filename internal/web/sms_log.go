@@ -0,0 +1,159 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 查询短信发送记录每页的默认条数与最大条数
+const (
+	smsLogDefaultLimit = 20
+	smsLogMaxLimit     = 100
+)
+
+// SmsLogHandler 客服排查短信问题使用的管理接口，只有管理员可以访问
+type SmsLogHandler struct {
+	svc    service.SmsLogService
+	admins map[int64]struct{}
+}
+
+func NewSmsLogHandler(svc service.SmsLogService, admins []int64) *SmsLogHandler {
+	h := &SmsLogHandler{
+		svc:    svc,
+		admins: make(map[int64]struct{}, len(admins)),
+	}
+	for _, uid := range admins {
+		h.admins[uid] = struct{}{}
+	}
+	return h
+}
+
+func (h *SmsLogHandler) RegisterRoutes(server *gin.Engine) {
+	ag := server.Group("/admin/sms", h.checkAdmin)
+	ag.GET("/logs", h.List)
+	ag.GET("/async/stats", h.AsyncStats)
+}
+
+// 登录校验由 JWT 中间件完成，这里只检查是不是管理员
+func (h *SmsLogHandler) checkAdmin(ctx *gin.Context) {
+	c, _ := ctx.Get("claims")
+	claims, ok := c.(*ijwt.UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if _, ok = h.admins[claims.Uid]; !ok {
+		ctx.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+}
+
+// List 按照手机号或者时间范围查询发送记录，时间使用 RFC 3339 格式
+func (h *SmsLogHandler) List(ctx *gin.Context) {
+	q := domain.SmsLogQuery{
+		Phone: ctx.Query("phone"),
+		Limit: smsLogDefaultLimit,
+	}
+
+	var err error
+	if start := ctx.Query("start"); start != "" {
+		q.Start, err = time.Parse(time.RFC3339, start)
+	}
+	if end := ctx.Query("end"); err == nil && end != "" {
+		q.End, err = time.Parse(time.RFC3339, end)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "时间格式不对......",
+		})
+		return
+	}
+
+	if q.Phone == "" && (q.Start.IsZero() || q.End.IsZero()) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请指定手机号或者完整的时间范围......",
+		})
+		return
+	}
+
+	if offset := ctx.Query("offset"); offset != "" {
+		q.Offset, err = strconv.Atoi(offset)
+	}
+	if limit := ctx.Query("limit"); err == nil && limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+	}
+	if err != nil || q.Offset < 0 || q.Limit <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "分页参数不对......",
+		})
+		return
+	}
+	q.Limit = min(q.Limit, smsLogMaxLimit)
+
+	logs, err := h.svc.Find(ctx, q)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	type SmsLog struct {
+		Id       int64  `json:"id"`
+		Biz      string `json:"biz"`
+		Phone    string `json:"phone"`
+		Tpl      string `json:"tpl"`
+		Provider string `json:"provider"`
+		Status   string `json:"status"`
+		Message  string `json:"message"`
+		// 耗时 ms
+		Latency int64  `json:"latency"`
+		Ctime   string `json:"ctime"`
+	}
+
+	res := make([]SmsLog, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, SmsLog{
+			Id:       l.Id,
+			Biz:      l.Biz,
+			Phone:    l.Phone,
+			Tpl:      l.Tpl,
+			Provider: l.Provider,
+			Status:   l.Status.String(),
+			Message:  l.Message,
+			Latency:  l.Latency.Milliseconds(),
+			Ctime:    l.Ctime.Format(time.RFC3339),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Data: res,
+	})
+}
+
+// AsyncStats 异步短信各个状态的数量，waiting 持续增长说明服务商一直不健康
+func (h *SmsLogHandler) AsyncStats(ctx *gin.Context) {
+	stats, err := h.svc.AsyncStats(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Data: stats,
+	})
+}
@@ -0,0 +1,169 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmocks "webook/internal/service/mock"
+	ijwt "webook/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSmsLogHandler_List(t *testing.T) {
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	testCases := []struct {
+		name string
+
+		mock     func(ctrl *gomock.Controller) service.SmsLogService
+		uid      int64
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "按照手机号查询",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				svc := svcmocks.NewMockSmsLogService(ctrl)
+				svc.EXPECT().Find(gomock.Any(), domain.SmsLogQuery{
+					Phone: "13812345678",
+					Limit: smsLogDefaultLimit,
+				}).Return([]domain.SmsLog{
+					{
+						Id:       1,
+						Biz:      "login",
+						Phone:    "138****5678",
+						Tpl:      "login_code",
+						Provider: "tencent",
+						Status:   domain.SmsStatusSuccess,
+						Message:  "OK",
+						Latency:  120 * time.Millisecond,
+						Ctime:    start,
+					},
+				}, nil)
+				return svc
+			},
+			uid:      1,
+			url:      "/admin/sms/logs?phone=13812345678",
+			wantCode: http.StatusOK,
+			wantBody: `{"code":0,"msg":"","data":[{"id":1,"biz":"login","phone":"138****5678",` +
+				`"tpl":"login_code","provider":"tencent","status":"success","message":"OK",` +
+				`"latency":120,"ctime":"2024-05-06T00:00:00Z"}]}`,
+		},
+		{
+			name: "按照时间范围查询，限制每页条数",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				svc := svcmocks.NewMockSmsLogService(ctrl)
+				svc.EXPECT().Find(gomock.Any(), domain.SmsLogQuery{
+					Start:  start,
+					End:    end,
+					Offset: 10,
+					Limit:  smsLogMaxLimit,
+				}).Return(nil, nil)
+				return svc
+			},
+			uid:      1,
+			url:      "/admin/sms/logs?start=2024-05-06T00:00:00Z&end=2024-05-07T00:00:00Z&offset=10&limit=1000",
+			wantCode: http.StatusOK,
+			wantBody: `{"code":0,"msg":"","data":[]}`,
+		},
+		{
+			name: "不是管理员",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				return svcmocks.NewMockSmsLogService(ctrl)
+			},
+			uid:      2,
+			url:      "/admin/sms/logs?phone=13812345678",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "没有查询条件",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				return svcmocks.NewMockSmsLogService(ctrl)
+			},
+			uid:      1,
+			url:      "/admin/sms/logs?start=2024-05-06T00:00:00Z",
+			wantCode: http.StatusOK,
+			wantBody: `{"code":4,"msg":"请指定手机号或者完整的时间范围......","data":null}`,
+		},
+		{
+			name: "时间格式不对",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				return svcmocks.NewMockSmsLogService(ctrl)
+			},
+			uid:      1,
+			url:      "/admin/sms/logs?start=2024-05-06&end=2024-05-07",
+			wantCode: http.StatusOK,
+			wantBody: `{"code":4,"msg":"时间格式不对......","data":null}`,
+		},
+		{
+			name: "系统错误",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				svc := svcmocks.NewMockSmsLogService(ctrl)
+				svc.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("mock db error"))
+				return svc
+			},
+			uid:      1,
+			url:      "/admin/sms/logs?phone=13812345678",
+			wantCode: http.StatusOK,
+			wantBody: `{"code":5,"msg":"系统错误......","data":null}`,
+		},
+		{
+			name: "异步短信积压情况",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				svc := svcmocks.NewMockSmsLogService(ctrl)
+				svc.EXPECT().AsyncStats(gomock.Any()).
+					Return(domain.AsyncSmsStats{Waiting: 3, Success: 10, Failed: 1}, nil)
+				return svc
+			},
+			uid:      1,
+			url:      "/admin/sms/async/stats",
+			wantCode: http.StatusOK,
+			wantBody: `{"code":0,"msg":"","data":{"waiting":3,"success":10,"failed":1}}`,
+		},
+		{
+			name: "不是管理员不能查看积压情况",
+			mock: func(ctrl *gomock.Controller) service.SmsLogService {
+				return svcmocks.NewMockSmsLogService(ctrl)
+			},
+			uid:      2,
+			url:      "/admin/sms/async/stats",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			// 模拟登录态
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{Uid: tc.uid})
+			})
+			h := NewSmsLogHandler(tc.mock(ctrl), []int64{1})
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantBody != "" {
+				assert.JSONEq(t, tc.wantBody, resp.Body.String())
+			}
+		})
+	}
+}
@@ -2,47 +2,74 @@ package web
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"time"
+	"unicode/utf8"
 	"webook/internal/domain"
 	"webook/internal/service"
+	ijwt "webook/internal/web/jwt"
+	"webook/pkg/mask"
 
 	regexp "github.com/dlclark/regexp2"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
-
-	jwt "github.com/golang-jwt/jwt/v5"
 )
 
-const biz = "login"
+// 验证码的业务，不同业务的验证码互不影响
+const (
+	bizLogin         = "login"
+	bizResetPassword = "reset_password"
+	bizBindPhone     = "bind_phone"
+	bizBindEmail     = "bind_email"
+	bizUnlock        = "unlock"
+)
 
-type UserClaims struct {
-	jwt.RegisteredClaims
-	Uid       int64 // 需要放入token中的数据
-	UserAgent string
-}
+// 用户可编辑信息的长度限制(按字符数计算)
+const (
+	nicknameMaxLen = 24
+	aboutMeMaxLen  = 1024
+)
 
 // 定义所有与用户相关的路由(Handler)
 type UserHandler struct {
 	emailExp    *regexp.Regexp
 	passwordExp *regexp.Regexp
+	phoneExp    *regexp.Regexp
 	svc         service.UserService
 	codeSvc     service.CodeService
+	// 邮件验证码
+	emailCodeSvc service.EmailCodeService
+	// 密码登录的防暴力破解
+	guard service.LoginGuardService
+	// 两步验证
+	totpSvc service.TotpService
+	ijwt.Handler
 }
 
-func NewUserHandler(svc service.UserService, codeSvc service.CodeService) *UserHandler {
+func NewUserHandler(svc service.UserService, codeSvc service.CodeService,
+	emailCodeSvc service.EmailCodeService, guard service.LoginGuardService,
+	totpSvc service.TotpService, jwtHdl ijwt.Handler) *UserHandler {
 	// 正则表达式校验请求用户注册信息
 	const (
 		emailRegexPattern = "^\\w+([-+.]\\w+)*@\\w+([-.]\\w+)*\\.\\w+([-.]\\w+)*$"
 		// 和上面比起来，用 ` 看起来就比较清爽
 		passwordRegexPattern = `^(?=.*[A-Za-z])(?=.*\d)(?=.*[$@$!%*#?&])[A-Za-z\d$@$!%*#?&]{8,72}$`
+		// 中国大陆手机号
+		phoneRegexPattern = `^1[3-9]\d{9}$`
 	)
 
 	return &UserHandler{
-		emailExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
-		passwordExp: regexp.MustCompile(passwordRegexPattern, regexp.None),
-		svc:         svc,
-		codeSvc:     codeSvc,
+		emailExp:     regexp.MustCompile(emailRegexPattern, regexp.None),
+		passwordExp:  regexp.MustCompile(passwordRegexPattern, regexp.None),
+		phoneExp:     regexp.MustCompile(phoneRegexPattern, regexp.None),
+		svc:          svc,
+		codeSvc:      codeSvc,
+		emailCodeSvc: emailCodeSvc,
+		guard:        guard,
+		totpSvc:      totpSvc,
+		Handler:      jwtHdl,
 	}
 }
 
@@ -52,11 +79,28 @@ func (u *UserHandler) RegisterRoutes(server *gin.Engine) {
 	ug.POST("/signup", u.SignUp)
 	// ug.POST("/login", u.Login)
 	ug.POST("/login", u.LoginJWT)
+	ug.POST("/login/unlock/code/send", u.SendUnlockCode)
+	ug.POST("/login/unlock", u.Unlock)
+	ug.POST("/login/2fa", u.LoginTwoFactor)
+	ug.POST("/2fa/totp/setup", u.SetupTotp)
+	ug.POST("/2fa/totp/enable", u.EnableTotp)
 	ug.POST("/edit", u.Edit)
 	// ug.GET("/profile", u.Profile)
 	ug.GET("/profile", u.ProfileJWT)
 	ug.POST("/login_sms/code/send", u.SendLoginSMSCode)
 	ug.POST("login_sms", u.LoginSMS)
+	ug.POST("/login_email/code/send", u.SendLoginEmailCode)
+	ug.POST("/login_email", u.LoginEmail)
+	ug.POST("/refresh_token", u.RefreshToken)
+	ug.POST("/logout", u.LogoutJWT)
+	ug.POST("/password/reset/code", u.SendResetPasswordCode)
+	ug.POST("/password/reset", u.ResetPassword)
+	// 以下修改账号凭证的接口需要登录
+	ug.POST("/password/change", u.ChangePassword)
+	ug.POST("/phone/code/send", u.SendBindPhoneCode)
+	ug.POST("/phone/bind", u.BindPhone)
+	ug.POST("/email/code/send", u.SendBindEmailCode)
+	ug.POST("/email/bind", u.BindEmail)
 }
 
 // 注册路由处理逻辑
@@ -134,12 +178,34 @@ func (u *UserHandler) LoginJWT(ctx *gin.Context) {
 		return
 	}
 
+	// 锁定期间不校验密码，避免继续尝试
+	ttl, err := u.guard.Check(ctx, req.Email, ctx.ClientIP())
+	if err == service.ErrLoginLocked {
+		ctx.String(http.StatusOK, lockedMsg(ttl))
+		return
+	}
+
+	if err != nil {
+		ctx.String(http.StatusOK, "系统错误......")
+		return
+	}
+
 	user, err := u.svc.Login(ctx, domain.User{
 		Email:    req.Email,
 		Password: req.Password,
 	})
 
 	if err == service.ErrInvalidUserOrPassword {
+		ttl, err = u.guard.Fail(ctx, req.Email, ctx.ClientIP())
+		if err == service.ErrLoginLocked {
+			ctx.String(http.StatusOK, lockedMsg(ttl))
+			return
+		}
+
+		if err != nil {
+			// 记录失败不影响本次的结果
+			log.Println("记录登录失败次数失败......", err)
+		}
 		ctx.String(http.StatusOK, "用户名或密码错误......")
 		return
 	}
@@ -149,35 +215,176 @@ func (u *UserHandler) LoginJWT(ctx *gin.Context) {
 		return
 	}
 
-	if err = u.setJWTToken(ctx, user.Id); err != nil {
+	if err = u.guard.Reset(ctx, req.Email); err != nil {
+		log.Println("清空登录失败次数失败......", err)
+	}
+
+	mfa, err := setLoginOrMfaToken(ctx, u.totpSvc, u.Handler, user.Id, req.Email)
+	if err != nil {
 		ctx.String(http.StatusOK, "系统错误......")
 		return
 	}
+
+	if mfa {
+		ctx.String(http.StatusOK, "需要两步验证......")
+		return
+	}
 	fmt.Println(user)
 
 	ctx.String(http.StatusOK, "登录成功......")
 }
 
-func (u *UserHandler) setJWTToken(ctx *gin.Context, uid int64) error {
-	claims := UserClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30)),
-		},
-		Uid:       uid,
-		UserAgent: ctx.Request.UserAgent(),
+// 使用 mfa token 与两步验证码(或者恢复码)换取登录态
+func (u *UserHandler) LoginTwoFactor(ctx *gin.Context) {
+	type Req struct {
+		Code string `json:"code"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	// 前端在 Authorization 头中带上 mfa token
+	mc, err := u.ParseMfaToken(ijwt.ExtractToken(ctx))
+	if err != nil || mc.UserAgent != ctx.Request.UserAgent() {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// 两步验证码同样可能被暴力破解，与密码共用失败次数
+	ttl, err := u.guard.Check(ctx, mc.Account, ctx.ClientIP())
+	if err == service.ErrLoginLocked {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  lockedMsg(ttl),
+		})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	err = u.totpSvc.Verify(ctx, mc.PendingUid, req.Code)
+	switch err {
+	case nil:
+	case service.ErrTotpInvalidCode:
+		ttl, err = u.guard.Fail(ctx, mc.Account, ctx.ClientIP())
+		if err == service.ErrLoginLocked {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  lockedMsg(ttl),
+			})
+			return
+		}
+
+		if err != nil {
+			log.Println("记录登录失败次数失败......", err)
+		}
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "两步验证码错误......",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if err = u.guard.Reset(ctx, mc.Account); err != nil {
+		log.Println("清空登录失败次数失败......", err)
+	}
+
+	if err = u.SetLoginToken(ctx, mc.PendingUid); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
 	}
 
-	// 使用JWT设置登录态
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	key := []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvf0")
-	tokenStr, err := token.SignedString(key)
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "登录成功......",
+	})
+}
+
+// 密码、短信、邮件、微信等第一步登录都从这里设置登录态，
+// 启用了两步验证的账号只拿到 mfa token，到 /users/login/2fa 换取真正的登录态
+func setLoginOrMfaToken(ctx *gin.Context, totpSvc service.TotpService, jwtHdl ijwt.Handler,
+	uid int64, account string) (bool, error) {
+	enabled, err := totpSvc.Enabled(ctx, uid)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	if enabled {
+		return true, jwtHdl.SetMfaToken(ctx, uid, account)
+	}
+
+	return false, jwtHdl.SetLoginToken(ctx, uid)
+}
+
+func lockedMsg(ttl time.Duration) string {
+	return fmt.Sprintf("登录失败次数过多，请 %d 分钟后再试，或者通过短信验证码解锁......",
+		int(math.Ceil(ttl.Minutes())))
+}
+
+// 使用 refresh token 换取新的 access token
+func (u *UserHandler) RefreshToken(ctx *gin.Context) {
+	// 前端在 Authorization 头中带上 refresh token
+	tokenStr := ijwt.ExtractToken(ctx)
+	rc, err := u.ParseRefreshToken(tokenStr)
+	if err != nil || rc.Uid == 0 {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if rc.UserAgent != ctx.Request.UserAgent() {
+		// refresh token 被别的设备使用，可能已经泄露
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// 已经退出登录的会话不能再换取 access token
+	if err = u.CheckSession(ctx, rc.Ssid); err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if err = u.SetJWTToken(ctx, rc.Uid, rc.Ssid); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "刷新成功......",
+	})
+}
+
+// 使用JWT时的退出登录，记录当前会话已经失效
+func (u *UserHandler) LogoutJWT(ctx *gin.Context) {
+	if err := u.ClearToken(ctx); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "退出登录失败......",
+		})
+		return
 	}
 
-	// 将生成的token写入到响应头
-	ctx.Header("x-jwt-token", tokenStr)
-	return nil
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "退出登录成功......",
+	})
 }
 
 // 用户登录处理逻辑
@@ -239,7 +446,90 @@ func (u *UserHandler) Logout(ctx *gin.Context) {
 
 // 用户信息编辑处理逻辑
 func (u *UserHandler) Edit(ctx *gin.Context) {
+	type EditReq struct {
+		Nickname string `json:"nickname"`
+		// 格式：2006-01-02
+		Birthday string `json:"birthday"`
+		AboutMe  string `json:"aboutMe"`
+	}
+
+	var req EditReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	c, ok := ctx.Get("claims")
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	claims, ok := c.(*ijwt.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if req.Nickname == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "昵称不能为空......",
+		})
+		return
+	}
+
+	if utf8.RuneCountInString(req.Nickname) > nicknameMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  fmt.Sprintf("昵称不能超过%d个字符......", nicknameMaxLen),
+		})
+		return
+	}
+
+	if utf8.RuneCountInString(req.AboutMe) > aboutMeMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  fmt.Sprintf("个人简介不能超过%d个字符......", aboutMeMaxLen),
+		})
+		return
+	}
+
+	var birthday time.Time
+	if req.Birthday != "" {
+		var err error
+		birthday, err = time.Parse(time.DateOnly, req.Birthday)
+		if err != nil || birthday.After(time.Now()) {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  "生日格式不对......",
+			})
+			return
+		}
+	}
+
+	err := u.svc.UpdateNonSensitiveInfo(ctx, domain.User{
+		Id:       claims.Uid,
+		Nickname: req.Nickname,
+		Birthday: birthday,
+		AboutMe:  req.AboutMe,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
 
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "更新成功......",
+	})
 }
 
 // 获取用户配置处理逻辑
@@ -248,20 +538,67 @@ func (u *UserHandler) Profile(ctx *gin.Context) {
 }
 
 func (u *UserHandler) ProfileJWT(ctx *gin.Context) {
+	// 返回给前端的用户信息，邮箱与手机号需要脱敏
+	type Profile struct {
+		Email    string `json:"email"`
+		Phone    string `json:"phone"`
+		Nickname string `json:"nickname"`
+		Birthday string `json:"birthday"`
+		AboutMe  string `json:"aboutMe"`
+		Ctime    string `json:"ctime"`
+	}
+
 	c, ok := ctx.Get("claims")
 	if !ok {
-		ctx.String(http.StatusOK, "系统错误......")
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
 		return
 	}
 
-	claims, ok := c.(*UserClaims)
+	claims, ok := c.(*ijwt.UserClaims)
 	if !ok {
-		ctx.String(http.StatusOK, "系统错误......")
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	user, err := u.svc.Profile(ctx, claims.Uid)
+	if err == service.ErrUserNotFound {
+		// token 还有效，但是用户已经被删除了
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "用户不存在......",
+		})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
 		return
 	}
 
-	fmt.Println("claims.Uid:", claims.Uid)
-	ctx.String(http.StatusOK, "profile......")
+	var birthday string
+	if !user.Birthday.IsZero() {
+		birthday = user.Birthday.Format(time.DateOnly)
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Data: Profile{
+			Email:    mask.Email(user.Email),
+			Phone:    mask.Phone(user.Phone),
+			Nickname: user.Nickname,
+			Birthday: birthday,
+			AboutMe:  user.AboutMe,
+			Ctime:    user.Ctime.Format(time.DateTime),
+		},
+	})
 }
 
 func (u *UserHandler) SendLoginSMSCode(ctx *gin.Context) {
@@ -274,15 +611,24 @@ func (u *UserHandler) SendLoginSMSCode(ctx *gin.Context) {
 		return
 	}
 
-	if req.Phone == "" {
+	isMatch, err := u.phoneExp.MatchString(req.Phone)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if !isMatch {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 4,
-			Msg:  "输入有误......",
+			Msg:  "手机号格式不对......",
 		})
 		return
 	}
 
-	err := u.codeSvc.Send(ctx, biz, req.Phone)
+	err = u.codeSvc.Send(ctx, bizLogin, req.Phone)
 	switch err {
 	case nil:
 		ctx.JSON(http.StatusOK, Result{
@@ -292,6 +638,11 @@ func (u *UserHandler) SendLoginSMSCode(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, Result{
 			Msg: "验证码发送频繁......",
 		})
+	case service.ErrCodeSendLimited:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "短信发送繁忙，请稍后再试......",
+		})
 	default:
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -311,16 +662,725 @@ func (u *UserHandler) LoginSMS(ctx *gin.Context) {
 		return
 	}
 
-	err := u.codeSvc.Verify(ctx, biz, req.Phone, req.Code)
+	isMatch, err := u.phoneExp.MatchString(req.Phone)
 	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "手机号格式不对......",
+		})
+		return
+	}
+
+	err = u.codeSvc.Verify(ctx, bizLogin, req.Phone, req.Code)
+	switch err {
+	case nil:
+	case service.ErrCodeVerifyTooManyTimes:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证次数过多，请重新获取验证码......",
+		})
+		return
+	case service.ErrCodeVerifyFailed:
 		ctx.JSON(http.StatusOK, Result{
 			Code: 4,
 			Msg:  "验证码错误......",
 		})
 		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	// 验证通过，手机号对应的用户不存在则自动注册
+	user, err := u.svc.FindOrCreate(ctx, req.Phone)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	mfa, err := setLoginOrMfaToken(ctx, u.totpSvc, u.Handler, user.Id, req.Phone)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if mfa {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "需要两步验证......",
+		})
+		return
 	}
 
 	ctx.JSON(http.StatusOK, Result{
-		Msg: "验证码校验通过......",
+		Msg: "登录成功......",
 	})
 }
+
+func (u *UserHandler) SendLoginEmailCode(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	isMatch, err := u.emailExp.MatchString(req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "邮箱格式不对......",
+		})
+		return
+	}
+
+	err = u.emailCodeSvc.Send(ctx, bizLogin, req.Email)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "验证码发送成功......",
+		})
+	case service.ErrCodeSendTooFrequently:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "验证码发送频繁......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+func (u *UserHandler) LoginEmail(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	isMatch, err := u.emailExp.MatchString(req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "邮箱格式不对......",
+		})
+		return
+	}
+
+	err = u.emailCodeSvc.Verify(ctx, bizLogin, req.Email, req.Code)
+	switch err {
+	case nil:
+	case service.ErrCodeVerifyTooManyTimes:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证次数过多，请重新获取验证码......",
+		})
+		return
+	case service.ErrCodeVerifyFailed:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证码错误......",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	// 验证通过，邮箱对应的用户不存在则自动注册
+	user, err := u.svc.FindOrCreateByEmail(ctx, req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	mfa, err := setLoginOrMfaToken(ctx, u.totpSvc, u.Handler, user.Id, req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if mfa {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "需要两步验证......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "登录成功......",
+	})
+}
+
+// 找回密码，验证码发送到手机号或者邮箱，二选一
+func (u *UserHandler) SendResetPasswordCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Email string `json:"email"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	codeSvc, target, ok := u.resetPasswordTarget(ctx, req.Phone, req.Email)
+	if !ok {
+		return
+	}
+
+	u.sendCode(ctx, codeSvc, bizResetPassword, target)
+}
+
+// 校验验证码之后重置密码，并让该用户所有已经登录的会话失效
+func (u *UserHandler) ResetPassword(ctx *gin.Context) {
+	type Req struct {
+		Phone           string `json:"phone"`
+		Email           string `json:"email"`
+		Code            string `json:"code"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	codeSvc, target, ok := u.resetPasswordTarget(ctx, req.Phone, req.Email)
+	if !ok {
+		return
+	}
+
+	if !u.checkPassword(ctx, req.Password, req.ConfirmPassword) {
+		return
+	}
+
+	if !u.verifyCode(ctx, codeSvc, bizResetPassword, target, req.Code) {
+		return
+	}
+
+	user, err := u.svc.ResetPassword(ctx, domain.User{
+		Phone:    req.Phone,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err == service.ErrUserNotFound {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "用户不存在......",
+		})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if err = u.RevokeSessions(ctx, user.Id); err != nil {
+		// 密码已经修改成功，验证码也已经用掉了，只能提示用户手动退出其他设备
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "密码已重置，退出其他设备的登录失败......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "密码重置成功......",
+	})
+}
+
+// 根据请求中的手机号或者邮箱选择验证码的发送渠道，校验失败时已经写好了响应
+func (u *UserHandler) resetPasswordTarget(ctx *gin.Context, phone, email string) (service.CodeService, string, bool) {
+	codeSvc, target, exp, msg := service.CodeService(u.codeSvc), phone, u.phoneExp, "手机号格式不对......"
+	if phone == "" {
+		codeSvc, target, exp, msg = u.emailCodeSvc, email, u.emailExp, "邮箱格式不对......"
+	}
+
+	if !u.matchTarget(ctx, exp, target, msg) {
+		return nil, "", false
+	}
+
+	return codeSvc, target, true
+}
+
+// 已经登录的用户修改密码，需要提供旧密码
+func (u *UserHandler) ChangePassword(ctx *gin.Context) {
+	type Req struct {
+		OldPassword     string `json:"oldPassword"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	claims, ok := u.claims(ctx)
+	if !ok {
+		return
+	}
+
+	if !u.checkPassword(ctx, req.Password, req.ConfirmPassword) {
+		return
+	}
+
+	err := u.svc.ChangePassword(ctx, claims.Uid, req.OldPassword, req.Password)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "密码修改成功......",
+		})
+	case service.ErrInvalidUserOrPassword:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "旧密码错误......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+// 绑定或者修改手机号，验证码发送到新手机号
+func (u *UserHandler) SendBindPhoneCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	if !u.matchTarget(ctx, u.phoneExp, req.Phone, "手机号格式不对......") {
+		return
+	}
+
+	u.sendCode(ctx, u.codeSvc, bizBindPhone, req.Phone)
+}
+
+func (u *UserHandler) BindPhone(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	claims, ok := u.claims(ctx)
+	if !ok {
+		return
+	}
+
+	if !u.matchTarget(ctx, u.phoneExp, req.Phone, "手机号格式不对......") {
+		return
+	}
+
+	if !u.verifyCode(ctx, u.codeSvc, bizBindPhone, req.Phone, req.Code) {
+		return
+	}
+
+	err := u.svc.UpdatePhone(ctx, claims.Uid, req.Phone)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "手机号绑定成功......",
+		})
+	case service.ErrUserDuplicatePhone:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "手机号已经被其他账号使用......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+// 修改邮箱，验证码发送到新邮箱，确认邮箱可用之后才会修改
+func (u *UserHandler) SendBindEmailCode(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	if !u.matchTarget(ctx, u.emailExp, req.Email, "邮箱格式不对......") {
+		return
+	}
+
+	u.sendCode(ctx, u.emailCodeSvc, bizBindEmail, req.Email)
+}
+
+func (u *UserHandler) BindEmail(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	claims, ok := u.claims(ctx)
+	if !ok {
+		return
+	}
+
+	if !u.matchTarget(ctx, u.emailExp, req.Email, "邮箱格式不对......") {
+		return
+	}
+
+	if !u.verifyCode(ctx, u.emailCodeSvc, bizBindEmail, req.Email, req.Code) {
+		return
+	}
+
+	err := u.svc.UpdateEmail(ctx, claims.Uid, req.Email)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "邮箱修改成功......",
+		})
+	case service.ErrUserDuplicateEmail:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "邮箱已经被其他账号使用......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+// 账号被锁定之后，向账号绑定的手机号发送解锁验证码
+func (u *UserHandler) SendUnlockCode(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	user, ok := u.unlockUser(ctx, req.Email)
+	if !ok {
+		return
+	}
+
+	u.sendCode(ctx, u.codeSvc, bizUnlock, user.Phone)
+}
+
+func (u *UserHandler) Unlock(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	user, ok := u.unlockUser(ctx, req.Email)
+	if !ok {
+		return
+	}
+
+	if !u.verifyCode(ctx, u.codeSvc, bizUnlock, user.Phone, req.Code) {
+		return
+	}
+
+	// 只解除账号的锁定，IP 的锁定继续生效
+	if err := u.guard.Reset(ctx, req.Email); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "解锁成功......",
+	})
+}
+
+// 找到需要解锁的账号，只有绑定了手机号的账号可以通过短信解锁，失败时已经写好了响应
+func (u *UserHandler) unlockUser(ctx *gin.Context, email string) (domain.User, bool) {
+	user, err := u.svc.FindByEmail(ctx, email)
+	switch {
+	case err == service.ErrUserNotFound || (err == nil && user.Phone == ""):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "账号没有绑定手机号，无法通过短信解锁......",
+		})
+		return domain.User{}, false
+	case err != nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return domain.User{}, false
+	}
+
+	return user, true
+}
+
+// 生成两步验证的密钥，前端把 uri 转成二维码给验证器扫描
+func (u *UserHandler) SetupTotp(ctx *gin.Context) {
+	claims, ok := u.claims(ctx)
+	if !ok {
+		return
+	}
+
+	secret, uri, err := u.totpSvc.Setup(ctx, claims.Uid)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: map[string]string{
+				"secret": secret,
+				"uri":    uri,
+			},
+		})
+	case service.ErrTotpEnabled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "已经启用两步验证......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+// 校验验证器生成的验证码之后启用两步验证，恢复码只会返回这一次
+func (u *UserHandler) EnableTotp(ctx *gin.Context) {
+	type Req struct {
+		Code string `json:"code"`
+	}
+
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	claims, ok := u.claims(ctx)
+	if !ok {
+		return
+	}
+
+	codes, err := u.totpSvc.Enable(ctx, claims.Uid, req.Code)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "两步验证启用成功，请妥善保存恢复码......",
+			Data: codes,
+		})
+	case service.ErrTotpInvalidCode:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "两步验证码错误......",
+		})
+	case service.ErrTotpNotSetup:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请先生成两步验证密钥......",
+		})
+	case service.ErrTotpEnabled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "已经启用两步验证......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+// 校验新密码的格式，失败时已经写好了响应
+func (u *UserHandler) checkPassword(ctx *gin.Context, password, confirmPassword string) bool {
+	if confirmPassword != password {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "两次输入的密码不一致......",
+		})
+		return false
+	}
+
+	isMatch, err := u.passwordExp.MatchString(password)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return false
+	}
+
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "密码必须大于8位，包含特殊数字与字符......",
+		})
+		return false
+	}
+
+	return true
+}
+
+// 取出登录态中的用户信息，失败时已经写好了响应
+func (u *UserHandler) claims(ctx *gin.Context) (*ijwt.UserClaims, bool) {
+	c, _ := ctx.Get("claims")
+	claims, ok := c.(*ijwt.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// 校验手机号或者邮箱的格式，失败时已经写好了响应
+func (u *UserHandler) matchTarget(ctx *gin.Context, exp *regexp.Regexp, target, msg string) bool {
+	isMatch, err := exp.MatchString(target)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return false
+	}
+
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  msg,
+		})
+		return false
+	}
+
+	return true
+}
+
+func (u *UserHandler) sendCode(ctx *gin.Context, codeSvc service.CodeService, biz, target string) {
+	err := codeSvc.Send(ctx, biz, target)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "验证码发送成功......",
+		})
+	case service.ErrCodeSendTooFrequently:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "验证码发送频繁......",
+		})
+	case service.ErrCodeSendLimited:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "短信发送繁忙，请稍后再试......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+}
+
+// 校验验证码，失败时已经写好了响应
+func (u *UserHandler) verifyCode(ctx *gin.Context, codeSvc service.CodeService, biz, target, code string) bool {
+	err := codeSvc.Verify(ctx, biz, target, code)
+	switch err {
+	case nil:
+		return true
+	case service.ErrCodeVerifyTooManyTimes:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证次数过多，请重新获取验证码......",
+		})
+	case service.ErrCodeVerifyFailed:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证码错误......",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+	}
+	return false
+}
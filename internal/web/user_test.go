@@ -2,13 +2,18 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/service"
 	svcmocks "webook/internal/service/mock"
+	ijwt "webook/internal/web/jwt"
+	jwtmocks "webook/internal/web/jwt/mock"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -163,7 +168,7 @@ func TestUserHandler_SignUp(t *testing.T) {
 			defer ctrl.Finish()
 
 			server := gin.Default()
-			h := NewUserHandler(tc.mock(ctrl), nil)
+			h := NewUserHandler(tc.mock(ctrl), nil, nil, nil, nil, nil)
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost, "/users/signup",
@@ -181,3 +186,1026 @@ func TestUserHandler_SignUp(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_Edit(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) service.UserService
+		reqBody  string
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "更新成功",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), domain.User{
+					Id:       123,
+					Nickname: "小明",
+					Birthday: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+					AboutMe:  "hello",
+				}).Return(nil)
+
+				return usersvc
+			},
+			reqBody:  `{"nickname": "小明", "birthday": "2000-01-01", "aboutMe": "hello"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Msg: "更新成功......"},
+		},
+		{
+			name: "昵称为空",
+			mock: func(controller *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(controller)
+			},
+			reqBody:  `{"nickname": "", "birthday": "2000-01-01"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "昵称不能为空......"},
+		},
+		{
+			name: "昵称过长",
+			mock: func(controller *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(controller)
+			},
+			reqBody:  `{"nickname": "` + strings.Repeat("名", nicknameMaxLen+1) + `"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "昵称不能超过24个字符......"},
+		},
+		{
+			name: "个人简介过长",
+			mock: func(controller *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(controller)
+			},
+			reqBody:  `{"nickname": "小明", "aboutMe": "` + strings.Repeat("a", aboutMeMaxLen+1) + `"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "个人简介不能超过1024个字符......"},
+		},
+		{
+			name: "生日格式不对",
+			mock: func(controller *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(controller)
+			},
+			reqBody:  `{"nickname": "小明", "birthday": "2000/01/01"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "生日格式不对......"},
+		},
+		{
+			name: "生日在未来",
+			mock: func(controller *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(controller)
+			},
+			reqBody:  `{"nickname": "小明", "birthday": "` + time.Now().AddDate(1, 0, 0).Format(time.DateOnly) + `"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "生日格式不对......"},
+		},
+		{
+			name: "系统错误",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), gomock.Any()).
+					Return(errors.New("mock db error"))
+
+				return usersvc
+			},
+			reqBody:  `{"nickname": "小明"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 5, Msg: "系统错误......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			// 模拟登录态
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{Uid: 123})
+			})
+			h := NewUserHandler(tc.mock(ctrl), nil, nil, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/edit",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			req.Header.Set("Content-Type", "application/json")
+
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_ProfileJWT(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) service.UserService
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "获取成功",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().Profile(gomock.Any(), int64(123)).Return(domain.User{
+					Id:       123,
+					Email:    "abc@qq.com",
+					Phone:    "13812345678",
+					Nickname: "小明",
+					Birthday: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+					AboutMe:  "hello",
+					Ctime:    time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local),
+				}, nil)
+
+				return usersvc
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"code":0,"msg":"","data":{"email":"a***c@qq.com","phone":"138****5678",` +
+				`"nickname":"小明","birthday":"2000-01-01","aboutMe":"hello","ctime":"2024-05-06 07:08:09"}}`,
+		},
+		{
+			name: "用户不存在",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().Profile(gomock.Any(), int64(123)).
+					Return(domain.User{}, service.ErrUserNotFound)
+
+				return usersvc
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"code":4,"msg":"用户不存在......","data":null}`,
+		},
+		{
+			name: "系统错误",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().Profile(gomock.Any(), int64(123)).
+					Return(domain.User{}, errors.New("mock db error"))
+
+				return usersvc
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"code":5,"msg":"系统错误......","data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			// 模拟登录态
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{Uid: 123})
+			})
+			h := NewUserHandler(tc.mock(ctrl), nil, nil, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, "/users/profile", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.JSONEq(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_LoginSMS(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler)
+		reqBody string
+		// 用户是否启用了两步验证
+		totpEnabled bool
+		wantCode    int
+		wantBody    Result
+	}{
+		{
+			name: "登录成功",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().FindOrCreate(gomock.Any(), "13812345678").
+					Return(domain.User{Id: 123, Phone: "13812345678"}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123)).Return(nil)
+
+				return usersvc, codesvc, jwtHdl
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Msg: "登录成功......"},
+		},
+		{
+			name: "启用了两步验证",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().FindOrCreate(gomock.Any(), "13812345678").
+					Return(domain.User{Id: 123, Phone: "13812345678"}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().SetMfaToken(gomock.Any(), int64(123), "13812345678").Return(nil)
+
+				return usersvc, codesvc, jwtHdl
+			},
+			reqBody:     `{"phone": "13812345678", "code": "123456"}`,
+			totpEnabled: true,
+			wantCode:    http.StatusOK,
+			wantBody:    Result{Msg: "需要两步验证......"},
+		},
+		{
+			name: "手机号格式不对",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler) {
+				return svcmocks.NewMockUserService(controller), svcmocks.NewMockCodeService(controller),
+					jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"phone": "12345", "code": "123456"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "手机号格式不对......"},
+		},
+		{
+			name: "验证码错误",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "13812345678", "123456").
+					Return(service.ErrCodeVerifyFailed)
+
+				return usersvc, codesvc, jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "验证码错误......"},
+		},
+		{
+			name: "验证次数过多",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "13812345678", "123456").
+					Return(service.ErrCodeVerifyTooManyTimes)
+
+				return usersvc, codesvc, jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "验证次数过多，请重新获取验证码......"},
+		},
+		{
+			name: "查找或创建用户失败",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().FindOrCreate(gomock.Any(), "13812345678").
+					Return(domain.User{}, errors.New("mock db error"))
+
+				return usersvc, codesvc, jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 5, Msg: "系统错误......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			usersvc, codesvc, jwtHdl := tc.mock(ctrl)
+			totpsvc := svcmocks.NewMockTotpService(ctrl)
+			totpsvc.EXPECT().Enabled(gomock.Any(), gomock.Any()).Return(tc.totpEnabled, nil).AnyTimes()
+			h := NewUserHandler(usersvc, codesvc, nil, nil, totpsvc, jwtHdl)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/login_sms",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			req.Header.Set("Content-Type", "application/json")
+
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_LoginEmail(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(controller *gomock.Controller) (service.UserService, service.EmailCodeService, ijwt.Handler)
+		reqBody string
+		// 用户是否启用了两步验证
+		totpEnabled bool
+		wantBody    Result
+	}{
+		{
+			name: "登录成功",
+			mock: func(controller *gomock.Controller) (service.UserService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockEmailCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "abc@qq.com", "123456").
+					Return(nil)
+				usersvc.EXPECT().FindOrCreateByEmail(gomock.Any(), "abc@qq.com").
+					Return(domain.User{Id: 123, Email: "abc@qq.com"}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123)).Return(nil)
+
+				return usersvc, codesvc, jwtHdl
+			},
+			reqBody:  `{"email": "abc@qq.com", "code": "123456"}`,
+			wantBody: Result{Msg: "登录成功......"},
+		},
+		{
+			name: "启用了两步验证",
+			mock: func(controller *gomock.Controller) (service.UserService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockEmailCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "abc@qq.com", "123456").
+					Return(nil)
+				usersvc.EXPECT().FindOrCreateByEmail(gomock.Any(), "abc@qq.com").
+					Return(domain.User{Id: 123, Email: "abc@qq.com"}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().SetMfaToken(gomock.Any(), int64(123), "abc@qq.com").Return(nil)
+
+				return usersvc, codesvc, jwtHdl
+			},
+			reqBody:     `{"email": "abc@qq.com", "code": "123456"}`,
+			totpEnabled: true,
+			wantBody:    Result{Msg: "需要两步验证......"},
+		},
+		{
+			name: "邮箱格式不对",
+			mock: func(controller *gomock.Controller) (service.UserService, service.EmailCodeService, ijwt.Handler) {
+				return svcmocks.NewMockUserService(controller), svcmocks.NewMockEmailCodeService(controller),
+					jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"email": "abc", "code": "123456"}`,
+			wantBody: Result{Code: 4, Msg: "邮箱格式不对......"},
+		},
+		{
+			name: "验证码错误",
+			mock: func(controller *gomock.Controller) (service.UserService, service.EmailCodeService, ijwt.Handler) {
+				codesvc := svcmocks.NewMockEmailCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "abc@qq.com", "123456").
+					Return(service.ErrCodeVerifyFailed)
+
+				return svcmocks.NewMockUserService(controller), codesvc, jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"email": "abc@qq.com", "code": "123456"}`,
+			wantBody: Result{Code: 4, Msg: "验证码错误......"},
+		},
+		{
+			name: "查找或创建用户失败",
+			mock: func(controller *gomock.Controller) (service.UserService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockEmailCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "login", "abc@qq.com", "123456").
+					Return(nil)
+				usersvc.EXPECT().FindOrCreateByEmail(gomock.Any(), "abc@qq.com").
+					Return(domain.User{}, errors.New("mock db error"))
+
+				return usersvc, codesvc, jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"email": "abc@qq.com", "code": "123456"}`,
+			wantBody: Result{Code: 5, Msg: "系统错误......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			usersvc, codesvc, jwtHdl := tc.mock(ctrl)
+			totpsvc := svcmocks.NewMockTotpService(ctrl)
+			totpsvc.EXPECT().Enabled(gomock.Any(), gomock.Any()).Return(tc.totpEnabled, nil).AnyTimes()
+			h := NewUserHandler(usersvc, nil, codesvc, nil, totpsvc, jwtHdl)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/login_email",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_RefreshToken(t *testing.T) {
+	const userAgent = "webook-test"
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) ijwt.Handler
+		wantCode int
+	}{
+		{
+			name: "刷新成功",
+			mock: func(controller *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseRefreshToken("refresh-token").Return(&ijwt.RefreshClaims{
+					Uid:       123,
+					Ssid:      "ssid-123",
+					UserAgent: userAgent,
+				}, nil)
+				jwtHdl.EXPECT().CheckSession(gomock.Any(), "ssid-123").Return(nil)
+				jwtHdl.EXPECT().SetJWTToken(gomock.Any(), int64(123), "ssid-123").Return(nil)
+				return jwtHdl
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "会话已经退出登录",
+			mock: func(controller *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseRefreshToken("refresh-token").Return(&ijwt.RefreshClaims{
+					Uid:       123,
+					Ssid:      "ssid-123",
+					UserAgent: userAgent,
+				}, nil)
+				jwtHdl.EXPECT().CheckSession(gomock.Any(), "ssid-123").Return(ijwt.ErrSessionRevoked)
+				return jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "refresh token 无效",
+			mock: func(controller *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseRefreshToken("refresh-token").Return(nil, ijwt.ErrInvalidToken)
+				return jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "设备不一致",
+			mock: func(controller *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseRefreshToken("refresh-token").Return(&ijwt.RefreshClaims{
+					Uid:       123,
+					Ssid:      "ssid-123",
+					UserAgent: "other-device",
+				}, nil)
+				return jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			h := NewUserHandler(nil, nil, nil, nil, nil, tc.mock(ctrl))
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/refresh_token", nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", userAgent)
+			req.Header.Set("Authorization", "Bearer refresh-token")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestUserHandler_ResetPassword(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler)
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "通过手机号重置成功",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "reset_password", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().ResetPassword(gomock.Any(), domain.User{
+					Phone:    "13812345678",
+					Password: "hello#world123",
+				}).Return(domain.User{Id: 123, Phone: "13812345678"}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().RevokeSessions(gomock.Any(), int64(123)).Return(nil)
+
+				return usersvc, codesvc, svcmocks.NewMockEmailCodeService(controller), jwtHdl
+			},
+			reqBody: `{"phone": "13812345678", "code": "123456", "password": "hello#world123",
+				"confirmPassword": "hello#world123"}`,
+			wantBody: Result{Msg: "密码重置成功......"},
+		},
+		{
+			name: "通过邮箱重置成功",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				emailsvc := svcmocks.NewMockEmailCodeService(controller)
+				emailsvc.EXPECT().Verify(gomock.Any(), "reset_password", "abc@qq.com", "123456").
+					Return(nil)
+				usersvc.EXPECT().ResetPassword(gomock.Any(), domain.User{
+					Email:    "abc@qq.com",
+					Password: "hello#world123",
+				}).Return(domain.User{Id: 123, Email: "abc@qq.com"}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().RevokeSessions(gomock.Any(), int64(123)).Return(nil)
+
+				return usersvc, svcmocks.NewMockCodeService(controller), emailsvc, jwtHdl
+			},
+			reqBody: `{"email": "abc@qq.com", "code": "123456", "password": "hello#world123",
+				"confirmPassword": "hello#world123"}`,
+			wantBody: Result{Msg: "密码重置成功......"},
+		},
+		{
+			name: "密码格式不对",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler) {
+				return svcmocks.NewMockUserService(controller), svcmocks.NewMockCodeService(controller),
+					svcmocks.NewMockEmailCodeService(controller), jwtmocks.NewMockHandler(controller)
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456", "password": "hello", "confirmPassword": "hello"}`,
+			wantBody: Result{Code: 4, Msg: "密码必须大于8位，包含特殊数字与字符......"},
+		},
+		{
+			name: "验证码错误",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler) {
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "reset_password", "13812345678", "123456").
+					Return(service.ErrCodeVerifyFailed)
+
+				return svcmocks.NewMockUserService(controller), codesvc,
+					svcmocks.NewMockEmailCodeService(controller), jwtmocks.NewMockHandler(controller)
+			},
+			reqBody: `{"phone": "13812345678", "code": "123456", "password": "hello#world123",
+				"confirmPassword": "hello#world123"}`,
+			wantBody: Result{Code: 4, Msg: "验证码错误......"},
+		},
+		{
+			name: "用户不存在",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "reset_password", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).
+					Return(domain.User{}, service.ErrUserNotFound)
+
+				return usersvc, codesvc, svcmocks.NewMockEmailCodeService(controller), jwtmocks.NewMockHandler(controller)
+			},
+			reqBody: `{"phone": "13812345678", "code": "123456", "password": "hello#world123",
+				"confirmPassword": "hello#world123"}`,
+			wantBody: Result{Code: 4, Msg: "用户不存在......"},
+		},
+		{
+			name: "退出已登录会话失败",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService, service.EmailCodeService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "reset_password", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().ResetPassword(gomock.Any(), gomock.Any()).
+					Return(domain.User{Id: 123}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().RevokeSessions(gomock.Any(), int64(123)).Return(errors.New("mock redis error"))
+
+				return usersvc, codesvc, svcmocks.NewMockEmailCodeService(controller), jwtHdl
+			},
+			reqBody: `{"phone": "13812345678", "code": "123456", "password": "hello#world123",
+				"confirmPassword": "hello#world123"}`,
+			wantBody: Result{Code: 5, Msg: "密码已重置，退出其他设备的登录失败......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			usersvc, codesvc, emailsvc, jwtHdl := tc.mock(ctrl)
+			h := NewUserHandler(usersvc, codesvc, emailsvc, nil, nil, jwtHdl)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/password/reset",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_ChangePassword(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) service.UserService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "修改成功",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().ChangePassword(gomock.Any(), int64(123), "hello#world123", "hello#world456").
+					Return(nil)
+				return usersvc
+			},
+			reqBody: `{"oldPassword": "hello#world123", "password": "hello#world456",
+				"confirmPassword": "hello#world456"}`,
+			wantBody: Result{Msg: "密码修改成功......"},
+		},
+		{
+			name: "两次输入的密码不一致",
+			mock: func(controller *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(controller)
+			},
+			reqBody: `{"oldPassword": "hello#world123", "password": "hello#world456",
+				"confirmPassword": "hello#world789"}`,
+			wantBody: Result{Code: 4, Msg: "两次输入的密码不一致......"},
+		},
+		{
+			name: "旧密码错误",
+			mock: func(controller *gomock.Controller) service.UserService {
+				usersvc := svcmocks.NewMockUserService(controller)
+				usersvc.EXPECT().ChangePassword(gomock.Any(), int64(123), "hello#world000", "hello#world456").
+					Return(service.ErrInvalidUserOrPassword)
+				return usersvc
+			},
+			reqBody: `{"oldPassword": "hello#world000", "password": "hello#world456",
+				"confirmPassword": "hello#world456"}`,
+			wantBody: Result{Code: 4, Msg: "旧密码错误......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			// 模拟登录态
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{Uid: 123})
+			})
+			h := NewUserHandler(tc.mock(ctrl), nil, nil, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/password/change",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_BindPhone(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) (service.UserService, service.CodeService)
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "绑定成功",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "bind_phone", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().UpdatePhone(gomock.Any(), int64(123), "13812345678").Return(nil)
+				return usersvc, codesvc
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantBody: Result{Msg: "手机号绑定成功......"},
+		},
+		{
+			name: "验证码错误",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService) {
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "bind_phone", "13812345678", "123456").
+					Return(service.ErrCodeVerifyFailed)
+				return svcmocks.NewMockUserService(controller), codesvc
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantBody: Result{Code: 4, Msg: "验证码错误......"},
+		},
+		{
+			name: "手机号已经被其他账号使用",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				codesvc := svcmocks.NewMockCodeService(controller)
+				codesvc.EXPECT().Verify(gomock.Any(), "bind_phone", "13812345678", "123456").
+					Return(nil)
+				usersvc.EXPECT().UpdatePhone(gomock.Any(), int64(123), "13812345678").
+					Return(service.ErrUserDuplicatePhone)
+				return usersvc, codesvc
+			},
+			reqBody:  `{"phone": "13812345678", "code": "123456"}`,
+			wantBody: Result{Code: 4, Msg: "手机号已经被其他账号使用......"},
+		},
+		{
+			name: "手机号格式不对",
+			mock: func(controller *gomock.Controller) (service.UserService, service.CodeService) {
+				return svcmocks.NewMockUserService(controller), svcmocks.NewMockCodeService(controller)
+			},
+			reqBody:  `{"phone": "12345", "code": "123456"}`,
+			wantBody: Result{Code: 4, Msg: "手机号格式不对......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			// 模拟登录态
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{Uid: 123})
+			})
+			usersvc, codesvc := tc.mock(ctrl)
+			h := NewUserHandler(usersvc, codesvc, nil, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/phone/bind",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_LoginJWT(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) (service.UserService, service.LoginGuardService, service.TotpService, ijwt.Handler)
+		wantBody string
+	}{
+		{
+			name: "登录成功",
+			mock: func(controller *gomock.Controller) (service.UserService, service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				usersvc.EXPECT().Login(gomock.Any(), domain.User{Email: "abc@qq.com", Password: "hello#world123"}).
+					Return(domain.User{Id: 123}, nil)
+				guard.EXPECT().Reset(gomock.Any(), "abc@qq.com").Return(nil)
+				totpsvc := svcmocks.NewMockTotpService(controller)
+				totpsvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(false, nil)
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123)).Return(nil)
+				return usersvc, guard, totpsvc, jwtHdl
+			},
+			wantBody: "登录成功......",
+		},
+		{
+			name: "需要两步验证",
+			mock: func(controller *gomock.Controller) (service.UserService, service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				usersvc.EXPECT().Login(gomock.Any(), gomock.Any()).Return(domain.User{Id: 123}, nil)
+				guard.EXPECT().Reset(gomock.Any(), "abc@qq.com").Return(nil)
+				totpsvc := svcmocks.NewMockTotpService(controller)
+				totpsvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(true, nil)
+				// 只发 mfa token，不设置登录态
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().SetMfaToken(gomock.Any(), int64(123), "abc@qq.com").Return(nil)
+				return usersvc, guard, totpsvc, jwtHdl
+			},
+			wantBody: "需要两步验证......",
+		},
+		{
+			name: "已经锁定，不校验密码",
+			mock: func(controller *gomock.Controller) (service.UserService, service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").
+					Return(90*time.Second, service.ErrLoginLocked)
+				return svcmocks.NewMockUserService(controller), guard, svcmocks.NewMockTotpService(controller),
+					jwtmocks.NewMockHandler(controller)
+			},
+			wantBody: "登录失败次数过多，请 2 分钟后再试，或者通过短信验证码解锁......",
+		},
+		{
+			name: "密码错误",
+			mock: func(controller *gomock.Controller) (service.UserService, service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				usersvc.EXPECT().Login(gomock.Any(), gomock.Any()).
+					Return(domain.User{}, service.ErrInvalidUserOrPassword)
+				guard.EXPECT().Fail(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				return usersvc, guard, svcmocks.NewMockTotpService(controller), jwtmocks.NewMockHandler(controller)
+			},
+			wantBody: "用户名或密码错误......",
+		},
+		{
+			name: "密码错误次数达到阈值",
+			mock: func(controller *gomock.Controller) (service.UserService, service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				usersvc := svcmocks.NewMockUserService(controller)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				usersvc.EXPECT().Login(gomock.Any(), gomock.Any()).
+					Return(domain.User{}, service.ErrInvalidUserOrPassword)
+				guard.EXPECT().Fail(gomock.Any(), "abc@qq.com", "192.0.2.1").
+					Return(time.Minute, service.ErrLoginLocked)
+				return usersvc, guard, svcmocks.NewMockTotpService(controller), jwtmocks.NewMockHandler(controller)
+			},
+			wantBody: "登录失败次数过多，请 1 分钟后再试，或者通过短信验证码解锁......",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			usersvc, guard, totpsvc, jwtHdl := tc.mock(ctrl)
+			h := NewUserHandler(usersvc, nil, nil, guard, totpsvc, jwtHdl)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/login",
+				bytes.NewBuffer([]byte(`{"email": "abc@qq.com", "password": "hello#world123"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.RemoteAddr = "192.0.2.1:12345"
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_LoginTwoFactor(t *testing.T) {
+	const userAgent = "webook-test"
+	mfaClaims := &ijwt.MfaClaims{PendingUid: 123, Account: "abc@qq.com", UserAgent: userAgent}
+	testCases := []struct {
+		name string
+
+		mock     func(controller *gomock.Controller) (service.LoginGuardService, service.TotpService, ijwt.Handler)
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "验证成功",
+			mock: func(controller *gomock.Controller) (service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseMfaToken("mfa-token").Return(mfaClaims, nil)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				totpsvc := svcmocks.NewMockTotpService(controller)
+				totpsvc.EXPECT().Verify(gomock.Any(), int64(123), "123456").Return(nil)
+				guard.EXPECT().Reset(gomock.Any(), "abc@qq.com").Return(nil)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123)).Return(nil)
+				return guard, totpsvc, jwtHdl
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{Msg: "登录成功......"},
+		},
+		{
+			name: "验证码错误",
+			mock: func(controller *gomock.Controller) (service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseMfaToken("mfa-token").Return(mfaClaims, nil)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				totpsvc := svcmocks.NewMockTotpService(controller)
+				totpsvc.EXPECT().Verify(gomock.Any(), int64(123), "123456").Return(service.ErrTotpInvalidCode)
+				guard.EXPECT().Fail(gomock.Any(), "abc@qq.com", "192.0.2.1").Return(time.Duration(0), nil)
+				return guard, totpsvc, jwtHdl
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "两步验证码错误......"},
+		},
+		{
+			name: "已经锁定",
+			mock: func(controller *gomock.Controller) (service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseMfaToken("mfa-token").Return(mfaClaims, nil)
+				guard := svcmocks.NewMockLoginGuardService(controller)
+				guard.EXPECT().Check(gomock.Any(), "abc@qq.com", "192.0.2.1").
+					Return(time.Minute, service.ErrLoginLocked)
+				return guard, svcmocks.NewMockTotpService(controller), jwtHdl
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "登录失败次数过多，请 1 分钟后再试，或者通过短信验证码解锁......"},
+		},
+		{
+			name: "mfa token 无效",
+			mock: func(controller *gomock.Controller) (service.LoginGuardService, service.TotpService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(controller)
+				jwtHdl.EXPECT().ParseMfaToken("mfa-token").Return(nil, ijwt.ErrInvalidToken)
+				return svcmocks.NewMockLoginGuardService(controller), svcmocks.NewMockTotpService(controller), jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			guard, totpsvc, jwtHdl := tc.mock(ctrl)
+			h := NewUserHandler(nil, nil, nil, guard, totpsvc, jwtHdl)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/login/2fa",
+				bytes.NewBuffer([]byte(`{"code": "123456"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", userAgent)
+			req.Header.Set("Authorization", "Bearer mfa-token")
+			req.RemoteAddr = "192.0.2.1:12345"
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"webook/internal/service"
+	"webook/internal/service/oauth2/wechat"
+	ijwt "webook/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// 保存 state 的 cookie，只在回调地址上携带
+	stateCookieName = "jwt-state"
+	stateCookiePath = "/oauth2/wechat/callback"
+	// 用户需要在这段时间内完成扫码
+	stateExpiration = time.Minute * 10
+)
+
+var errStateMismatch = errors.New("state 不一致")
+
+// OAuth2WechatHandler 微信扫码登录
+type OAuth2WechatHandler struct {
+	svc     wechat.Service
+	userSvc service.UserService
+	totpSvc service.TotpService
+	// 签名 state cookie 使用的 key
+	stateKey []byte
+	ijwt.Handler
+}
+
+func NewOAuth2WechatHandler(svc wechat.Service, userSvc service.UserService, totpSvc service.TotpService,
+	stateKey []byte, jwtHdl ijwt.Handler) *OAuth2WechatHandler {
+	return &OAuth2WechatHandler{
+		svc:      svc,
+		userSvc:  userSvc,
+		totpSvc:  totpSvc,
+		stateKey: stateKey,
+		Handler:  jwtHdl,
+	}
+}
+
+func (h *OAuth2WechatHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/oauth2/wechat")
+	g.GET("/authurl", h.AuthURL)
+	g.Any("/callback", h.Callback)
+}
+
+// StateClaims 保存在 cookie 中，回调时与微信带回的 state 比较，防止 CSRF
+type StateClaims struct {
+	jwt.RegisteredClaims
+	State string
+}
+
+// AuthURL 返回跳转到微信扫码页面的地址
+func (h *OAuth2WechatHandler) AuthURL(ctx *gin.Context) {
+	state := uuid.New().String()
+	url, err := h.svc.AuthURL(ctx, state)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "构造扫码登录 URL 失败......",
+		})
+		return
+	}
+
+	if err = h.setStateCookie(ctx, state); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Data: url,
+	})
+}
+
+// Callback 微信扫码之后跳转回来，使用授权码换取用户标识并登录
+func (h *OAuth2WechatHandler) Callback(ctx *gin.Context) {
+	if err := h.verifyState(ctx); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "登录失败......",
+		})
+		return
+	}
+
+	info, err := h.svc.VerifyCode(ctx, ctx.Query("code"))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	user, err := h.userSvc.FindOrCreateByWechat(ctx, info)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	mfa, err := setLoginOrMfaToken(ctx, h.totpSvc, h.Handler, user.Id, info.OpenId)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误......",
+		})
+		return
+	}
+
+	if mfa {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "需要两步验证......",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "登录成功......",
+	})
+}
+
+func (h *OAuth2WechatHandler) setStateCookie(ctx *gin.Context, state string) error {
+	claims := StateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(stateExpiration)),
+		},
+		State: state,
+	}
+	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(h.stateKey)
+	if err != nil {
+		return err
+	}
+
+	ctx.SetCookie(stateCookieName, tokenStr, int(stateExpiration.Seconds()), stateCookiePath,
+		"", true, true)
+	return nil
+}
+
+func (h *OAuth2WechatHandler) verifyState(ctx *gin.Context) error {
+	cookie, err := ctx.Cookie(stateCookieName)
+	if err != nil {
+		return fmt.Errorf("拿不到 state cookie: %w", err)
+	}
+
+	var claims StateClaims
+	_, err = jwt.ParseWithClaims(cookie, &claims, func(token *jwt.Token) (any, error) {
+		return h.stateKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Alg()}))
+	if err != nil {
+		return fmt.Errorf("state cookie 无效: %w", err)
+	}
+
+	state := ctx.Query("state")
+	if state == "" || state != claims.State {
+		return errStateMismatch
+	}
+
+	// state 只能使用一次
+	ctx.SetCookie(stateCookieName, "", -1, stateCookiePath, "", true, true)
+	return nil
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmocks "webook/internal/service/mock"
+	"webook/internal/service/oauth2/wechat"
+	wechatmocks "webook/internal/service/oauth2/wechat/mock"
+	ijwt "webook/internal/web/jwt"
+	jwtmocks "webook/internal/web/jwt/mock"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testStateKey = []byte(strings.Repeat("k", 64))
+
+func TestOAuth2WechatHandler_Callback(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (wechat.Service, service.UserService, ijwt.Handler)
+		// 回调时带回的 state，为空时使用 authurl 中的 state
+		state string
+		// 不带 cookie，例如被别的站点诱导访问回调地址
+		noCookie bool
+		// 用户是否启用了两步验证
+		totpEnabled bool
+		wantBody    Result
+	}{
+		{
+			name: "登录成功",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, service.UserService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com/xxx", nil)
+				svc.EXPECT().VerifyCode(gomock.Any(), "auth-code").
+					Return(domain.WechatInfo{OpenId: "open-id", UnionId: "union-id"}, nil)
+				usersvc := svcmocks.NewMockUserService(ctrl)
+				usersvc.EXPECT().FindOrCreateByWechat(gomock.Any(),
+					domain.WechatInfo{OpenId: "open-id", UnionId: "union-id"}).
+					Return(domain.User{Id: 123}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123)).Return(nil)
+				return svc, usersvc, jwtHdl
+			},
+			wantBody: Result{Msg: "登录成功......"},
+		},
+		{
+			name: "启用了两步验证",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, service.UserService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com/xxx", nil)
+				svc.EXPECT().VerifyCode(gomock.Any(), "auth-code").
+					Return(domain.WechatInfo{OpenId: "open-id", UnionId: "union-id"}, nil)
+				usersvc := svcmocks.NewMockUserService(ctrl)
+				usersvc.EXPECT().FindOrCreateByWechat(gomock.Any(),
+					domain.WechatInfo{OpenId: "open-id", UnionId: "union-id"}).
+					Return(domain.User{Id: 123}, nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetMfaToken(gomock.Any(), int64(123), "open-id").Return(nil)
+				return svc, usersvc, jwtHdl
+			},
+			totpEnabled: true,
+			wantBody:    Result{Msg: "需要两步验证......"},
+		},
+		{
+			name: "state 不一致",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, service.UserService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com/xxx", nil)
+				return svc, svcmocks.NewMockUserService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			state:    "other-state",
+			wantBody: Result{Code: 4, Msg: "登录失败......"},
+		},
+		{
+			name: "没有 state cookie",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, service.UserService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com/xxx", nil)
+				return svc, svcmocks.NewMockUserService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			noCookie: true,
+			wantBody: Result{Code: 4, Msg: "登录失败......"},
+		},
+		{
+			name: "换取用户标识失败",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, service.UserService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com/xxx", nil)
+				svc.EXPECT().VerifyCode(gomock.Any(), "auth-code").
+					Return(domain.WechatInfo{}, errors.New("mock wechat error"))
+				return svc, svcmocks.NewMockUserService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			wantBody: Result{Code: 5, Msg: "系统错误......"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			svc, usersvc, jwtHdl := tc.mock(ctrl)
+			totpsvc := svcmocks.NewMockTotpService(ctrl)
+			totpsvc.EXPECT().Enabled(gomock.Any(), gomock.Any()).Return(tc.totpEnabled, nil).AnyTimes()
+			var state string
+			h := NewOAuth2WechatHandler(&stateRecorder{Service: svc, state: &state}, usersvc, totpsvc,
+				testStateKey, jwtHdl)
+			h.RegisterRoutes(server)
+
+			// 先获取扫码地址，拿到 state cookie
+			req := httptest.NewRequest(http.MethodGet, "/oauth2/wechat/authurl", nil)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			var res Result
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+			assert.Equal(t, "https://open.weixin.qq.com/xxx", res.Data)
+			cookies := resp.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, "/oauth2/wechat/callback", cookies[0].Path)
+			assert.True(t, cookies[0].HttpOnly)
+
+			if tc.state != "" {
+				state = tc.state
+			}
+			q := url.Values{"code": {"auth-code"}, "state": {state}}
+			req = httptest.NewRequest(http.MethodGet, "/oauth2/wechat/callback?"+q.Encode(), nil)
+			if !tc.noCookie {
+				req.AddCookie(cookies[0])
+			}
+			resp = httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			res = Result{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+// 记录 handler 生成的 state，模拟微信回调时原样带回
+type stateRecorder struct {
+	wechat.Service
+	state *string
+}
+
+func (r *stateRecorder) AuthURL(ctx context.Context, state string) (string, error) {
+	*r.state = state
+	return r.Service.AuthURL(ctx, state)
+}
@@ -0,0 +1,48 @@
+package ioc
+
+import (
+	"webook/config"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 单机部署可以使用进程内的验证码缓存，不依赖 Redis
+func InitCodeCache(cfg *config.Config, cmd redis.Cmdable) cache.CodeCache {
+	if cfg.CodeCache.Type == "local" {
+		return cache.NewLocalCodeCache(cfg.CodeCache.Capacity)
+	}
+	return cache.NewCodeCache(cmd)
+}
+
+func InitCodePolicies(cfg *config.Config) service.CodePolicies {
+	policies := make(service.CodePolicies, len(cfg.Code))
+	for biz, p := range cfg.Code {
+		policies[biz] = domain.CodePolicy{
+			Length:         p.Length,
+			Alphabet:       p.Alphabet,
+			TTL:            p.TTL,
+			ResendInterval: p.ResendInterval,
+			MaxAttempts:    p.MaxAttempts,
+		}
+	}
+	return policies
+}
+
+func InitLoginLockPolicies(cfg *config.Config) service.LoginLockPolicies {
+	return service.LoginLockPolicies{
+		Account: newLoginLockPolicy(cfg.LoginLock.Account),
+		IP:      newLoginLockPolicy(cfg.LoginLock.IP),
+	}
+}
+
+func newLoginLockPolicy(cfg config.LoginLockPolicyConfig) domain.LoginLockPolicy {
+	return domain.LoginLockPolicy{
+		Threshold: cfg.Threshold,
+		BaseLock:  cfg.BaseLock,
+		MaxLock:   cfg.MaxLock,
+		Window:    cfg.Window,
+	}
+}
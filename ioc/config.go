@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"os"
+	"webook/config"
+)
+
+// 加载配置并监听配置文件变更
+func InitConfigManager() *config.Manager {
+	m, err := config.NewManager(os.Args[1:])
+	if err != nil {
+		panic(err)
+	}
+
+	m.Watch()
+	return m
+}
+
+// 启动时的配置快照，用于不支持热更新的组件
+func InitConfig(m *config.Manager) *config.Config {
+	return m.Config()
+}
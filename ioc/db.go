@@ -8,9 +8,8 @@ import (
 	"gorm.io/gorm"
 )
 
-func InitDB() *gorm.DB {
-	dns := config.Config.DB.DNS
-	db, err := gorm.Open(mysql.Open(dns), &gorm.Config{})
+func InitDB(cfg *config.Config) *gorm.DB {
+	db, err := gorm.Open(mysql.Open(cfg.DB.DNS), &gorm.Config{})
 	if err != nil {
 		panic(err)
 	}
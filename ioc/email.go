@@ -0,0 +1,18 @@
+package ioc
+
+import (
+	"webook/config"
+	"webook/internal/service/email"
+	"webook/internal/service/email/memory"
+	"webook/internal/service/email/smtp"
+)
+
+func InitEmailService(cfg *config.Config) email.Service {
+	switch cfg.Email.Provider {
+	case "smtp":
+		s := cfg.Email.SMTP
+		return smtp.NewService(s.Host, s.Port, s.Username, s.Password, s.From, s.Timeout)
+	default:
+		return memory.NewService()
+	}
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"webook/config"
+	ijwt "webook/internal/web/jwt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func InitJWTHandler(cfg *config.Config, cmd redis.Cmdable) ijwt.Handler {
+	atKeys, err := ijwt.NewKeySet(cfg.JWT.Access)
+	if err != nil {
+		panic(err)
+	}
+
+	rtKeys, err := ijwt.NewKeySet(cfg.JWT.Refresh)
+	if err != nil {
+		panic(err)
+	}
+
+	return ijwt.NewRedisJWTHandler(cmd, atKeys, rtKeys)
+}
@@ -6,9 +6,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func InitRedis() redis.Cmdable {
+func InitRedis(cfg *config.Config) redis.Cmdable {
 	redisClient := redis.NewClient(&redis.Options{
-		Addr: config.Config.Redis.Addr,
+		Addr: cfg.Redis.Addr,
 	})
 
 	return redisClient
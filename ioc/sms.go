@@ -1,10 +1,175 @@
 package ioc
 
 import (
+	"log"
+	"reflect"
+	"webook/config"
+	"webook/internal/repository"
+	"webook/internal/repository/dao"
 	"webook/internal/service/sms"
+	"webook/internal/service/sms/aliyun"
+	"webook/internal/service/sms/async"
+	"webook/internal/service/sms/audit"
+	"webook/internal/service/sms/auth"
+	"webook/internal/service/sms/failover"
 	"webook/internal/service/sms/memory"
+	"webook/internal/service/sms/ratelimit"
+	"webook/internal/service/sms/switchable"
+	"webook/internal/service/sms/tencent"
+	"webook/internal/service/sms/validator"
+	"webook/pkg/limiter"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	tencentsms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
 )
 
-func InitSMSService() sms.Service {
-	return memory.NewService()
+// 先认证调用方，再校验模板参数，再限流，超出配额的请求直接拒绝，不进入异步队列。
+// 验证码同样作为一个调用方，只能使用配置中允许的模板
+func InitSMSService(m *config.Manager, svc *async.Service, cmd redis.Cmdable,
+	templates *sms.Templates) sms.Service {
+	cfg := m.Config().SMS.RateLimit
+	l := limiter.NewRedisSlidingWindowLimiter(cmd, cfg.Interval, cfg.Rate)
+	m.Subscribe(func(old, cur *config.Config) {
+		if old.SMS.RateLimit != cur.SMS.RateLimit {
+			l.SetLimit(cur.SMS.RateLimit.Interval, cur.SMS.RateLimit.Rate)
+		}
+	})
+	// key 与白名单不支持热加载，修改之后需要重启
+	a := m.Config().SMS.Auth
+	key := []byte(a.Key)
+	return auth.NewSigner(auth.NewService(validator.NewService(ratelimit.NewService(svc, l), templates),
+		key, a.BizTemplates), key)
+}
+
+// 所有服务商共享同一个模板注册表，配置变更之后整体替换
+func InitSMSTemplates(m *config.Manager) *sms.Templates {
+	templates := sms.NewTemplates(newSMSTemplates(m.Config().SMS.Templates)...)
+	m.Subscribe(func(old, cur *config.Config) {
+		if !reflect.DeepEqual(old.SMS.Templates, cur.SMS.Templates) {
+			templates.Reset(newSMSTemplates(cur.SMS.Templates)...)
+		}
+	})
+	return templates
+}
+
+func newSMSTemplates(cfgs []config.SMSTemplateConfig) []sms.Template {
+	tpls := make([]sms.Template, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		tpls = append(tpls, sms.Template{
+			Name:        cfg.Name,
+			Params:      cfg.Params,
+			ProviderIds: cfg.Ids,
+		})
+	}
+	return tpls
+}
+
+// 手机号哈希的 key 不支持热加载，修改之后需要重启
+func InitSmsLogRepository(cfg *config.Config, d dao.SmsLogDAO) repository.SmsLogRepository {
+	return repository.NewSmsLogRepository(d, []byte(cfg.SMS.LogHashKey))
+}
+
+// 服务商不健康时转为异步发送，后台 worker 的启停由 App 管理
+func InitAsyncSMSService(m *config.Manager, repo repository.AsyncSmsRepository,
+	templates *sms.Templates, logRepo repository.SmsLogRepository) *async.Service {
+	cfg := m.Config().SMS.Async
+	return async.NewService(initProviderSMSService(m, templates, logRepo), repo, async.Options{
+		WindowSize:       cfg.WindowSize,
+		MinSamples:       cfg.MinSamples,
+		ErrRateThreshold: cfg.ErrRateThreshold,
+		LatencyThreshold: cfg.LatencyThreshold,
+		Workers:          cfg.Workers,
+		PollInterval:     cfg.PollInterval,
+		RetryMax:         cfg.RetryMax,
+		BaseBackoff:      cfg.BaseBackoff,
+		MaxBackoff:       cfg.MaxBackoff,
+		SendTimeout:      cfg.SendTimeout,
+	})
+}
+
+// 实际发送短信的服务商
+func initProviderSMSService(m *config.Manager, templates *sms.Templates,
+	logRepo repository.SmsLogRepository) sms.Service {
+	svc, err := newSMSService(m.Config().SMS, templates, logRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	s := switchable.NewService(svc)
+	// 短信服务商的配置变更之后切换到新的服务商
+	m.Subscribe(func(old, cur *config.Config) {
+		if reflect.DeepEqual(old.SMS, cur.SMS) {
+			return
+		}
+
+		svc, err := newSMSService(cur.SMS, templates, logRepo)
+		if err != nil {
+			log.Println("切换短信服务商失败......", err)
+			return
+		}
+
+		s.Switch(svc)
+	})
+
+	return s
+}
+
+func newSMSService(cfg config.SMSConfig, templates *sms.Templates,
+	logRepo repository.SmsLogRepository) (sms.Service, error) {
+	if len(cfg.Failover.Providers) == 0 {
+		return newSMSProvider(cfg, cfg.Provider, templates, logRepo)
+	}
+
+	svcs := make([]sms.Service, 0, len(cfg.Failover.Providers))
+	for _, provider := range cfg.Failover.Providers {
+		svc, err := newSMSProvider(cfg, provider, templates, logRepo)
+		if err != nil {
+			return nil, err
+		}
+		svcs = append(svcs, svc)
+	}
+
+	switch cfg.Failover.Strategy {
+	case "timeout":
+		return failover.NewTimeoutFailoverService(svcs, cfg.Failover.TimeoutThreshold), nil
+	default:
+		return failover.NewFailoverService(svcs), nil
+	}
+}
+
+// 每个服务商的每一次发送都会记录下来
+func newSMSProvider(cfg config.SMSConfig, provider string, templates *sms.Templates,
+	logRepo repository.SmsLogRepository) (sms.Service, error) {
+	var svc sms.Service
+	switch provider {
+	case "tencent":
+		var err error
+		svc, err = newTencentSMSService(cfg.Tencent, templates)
+		if err != nil {
+			return nil, err
+		}
+	case "aliyun":
+		svc = newAliyunSMSService(cfg.Aliyun, templates)
+	default:
+		provider = "memory"
+		svc = memory.NewService()
+	}
+	return audit.NewService(svc, provider, logRepo), nil
+}
+
+func newTencentSMSService(cfg config.TencentSMSConfig, templates *sms.Templates) (sms.Service, error) {
+	client, err := tencentsms.NewClient(common.NewCredential(cfg.SecretId, cfg.SecretKey),
+		cfg.Region, profile.NewClientProfile())
+	if err != nil {
+		return nil, err
+	}
+
+	return tencent.NewService(cfg.AppId, cfg.SignName, client, templates), nil
+}
+
+func newAliyunSMSService(cfg config.AliyunSMSConfig, templates *sms.Templates) sms.Service {
+	client := aliyun.NewClient(cfg.Endpoint, cfg.RegionId, cfg.AccessKeyId, cfg.AccessKeySecret)
+	return aliyun.NewService(client, cfg.SignName, templates)
 }
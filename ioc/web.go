@@ -1,49 +1,111 @@
 package ioc
 
 import (
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
+	"webook/config"
+	"webook/internal/service"
 	"webook/internal/web"
+	ijwt "webook/internal/web/jwt"
 	"webook/internal/web/middleware"
 	"webook/pkg/ginx/middlewares/ratelimit"
+	"webook/pkg/limiter"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitWebServer(middlewares []gin.HandlerFunc, userHandler *web.UserHandler) *gin.Engine {
+func InitWebServer(cfg *config.Config, middlewares []gin.HandlerFunc, userHandler *web.UserHandler,
+	healthHandler *web.HealthHandler, smsLogHandler *web.SmsLogHandler,
+	wechatHandler *web.OAuth2WechatHandler) *gin.Engine {
 	server := gin.Default()
+	// gin 默认信任所有代理，客户端可以伪造 X-Forwarded-For 绕过按 IP 的限流与登录锁定
+	if err := server.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		panic(err)
+	}
 	server.Use(middlewares...)
 	userHandler.RegisterRoutes(server)
+	healthHandler.RegisterRoutes(server)
+	smsLogHandler.RegisterRoutes(server)
+	// 没有配置 AppID 时不启用微信登录
+	if cfg.Wechat.AppId != "" {
+		wechatHandler.RegisterRoutes(server)
+	}
 	return server
 }
 
-func InitMiddlewares(redisClient redis.Cmdable) []gin.HandlerFunc {
+func InitSmsLogHandler(cfg *config.Config, svc service.SmsLogService) *web.SmsLogHandler {
+	return web.NewSmsLogHandler(svc, cfg.Admin.Uids)
+}
+
+func InitHTTPServer(cfg *config.Config, server *gin.Engine) *http.Server {
+	return &http.Server{
+		Addr:    cfg.HTTP.Addr,
+		Handler: server,
+	}
+}
+
+func InitMiddlewares(m *config.Manager, redisClient redis.Cmdable,
+	jwtHdl ijwt.Handler) []gin.HandlerFunc {
+	cfg := m.Config()
+	// k8s 探针不能被限流，否则流量高峰时 pod 会被摘除
+	l := limiter.NewRedisSlidingWindowLimiter(redisClient, cfg.RateLimit.Interval, cfg.RateLimit.Rate)
+	m.Subscribe(func(old, cur *config.Config) {
+		if old.RateLimit != cur.RateLimit {
+			l.SetLimit(cur.RateLimit.Interval, cur.RateLimit.Rate)
+		}
+	})
+
 	return []gin.HandlerFunc{
-		corsHandler(),
-		middleware.NewLoginJWTMiddlewareBuilder().
+		corsHandler(m),
+		middleware.NewLoginJWTMiddlewareBuilder(jwtHdl).
 			IgnorePaths("/users/login").
+			IgnorePaths("/users/login/unlock/code/send").
+			IgnorePaths("/users/login/unlock").
+			IgnorePaths("/users/login/2fa").
+			IgnorePaths("/oauth2/wechat/authurl").
+			IgnorePaths("/oauth2/wechat/callback").
 			IgnorePaths("/users/signup").
 			IgnorePaths("/users/login_sms/code/send").
 			IgnorePaths("/users/login_sms").
+			IgnorePaths("/users/login_email/code/send").
+			IgnorePaths("/users/login_email").
+			IgnorePaths("/users/refresh_token").
+			IgnorePaths("/users/password/reset/code").
+			IgnorePaths("/users/password/reset").
+			IgnorePaths("/health/live").
+			IgnorePaths("/health/ready").
+			Build(),
+		ratelimit.NewBuilder(l).
+			IgnorePaths("/health/live").
+			IgnorePaths("/health/ready").
 			Build(),
-		ratelimit.NewBuilder(redisClient, time.Second, 100).Build(),
 	}
 }
 
-func corsHandler() gin.HandlerFunc {
+func corsHandler(m *config.Manager) gin.HandlerFunc {
+	// 允许跨域的 origin 可以在运行时调整
+	var allowOrigins atomic.Pointer[[]string]
+	allowOrigins.Store(&m.Config().CORS.AllowOrigins)
+	m.Subscribe(func(old, cur *config.Config) {
+		allowOrigins.Store(&cur.CORS.AllowOrigins)
+	})
+
 	return cors.New(cors.Config{
 		AllowHeaders:     []string{"Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"x-jwt-token"}, // 允许客户端获取的响应头
+		ExposeHeaders:    []string{"x-jwt-token", "x-refresh-token", "x-mfa-token"}, // 允许客户端获取的响应头
 		AllowCredentials: true,
 		// 自定义origin
 		AllowOriginFunc: func(origin string) bool {
-			if strings.HasPrefix(origin, "http://localhost") {
-				return true
+			for _, allow := range *allowOrigins.Load() {
+				if strings.Contains(origin, allow) {
+					return true
+				}
 			}
-			// TODO
-			return strings.Contains(origin, "company.com")
+			return false
 		},
 		MaxAge: 12 * time.Hour,
 	})
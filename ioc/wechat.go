@@ -0,0 +1,31 @@
+package ioc
+
+import (
+	"crypto/rand"
+	"net/http"
+	"time"
+	"webook/config"
+	"webook/internal/service"
+	"webook/internal/service/oauth2/wechat"
+	"webook/internal/web"
+	ijwt "webook/internal/web/jwt"
+)
+
+func InitWechatService(cfg *config.Config) wechat.Service {
+	w := cfg.Wechat
+	return wechat.NewService(w.AppId, w.AppSecret, w.RedirectURL, w.APIBase,
+		&http.Client{Timeout: 5 * time.Second})
+}
+
+func InitOAuth2WechatHandler(cfg *config.Config, svc wechat.Service, userSvc service.UserService,
+	totpSvc service.TotpService, jwtHdl ijwt.Handler) *web.OAuth2WechatHandler {
+	key := []byte(cfg.Wechat.StateKey)
+	if len(key) == 0 {
+		// 没有启用微信登录，使用随机的 key，不需要在多个实例之间保持一致
+		key = make([]byte, 64)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+	}
+	return web.NewOAuth2WechatHandler(svc, userSvc, totpSvc, key, jwtHdl)
+}
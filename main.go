@@ -1,6 +1,10 @@
 package main
 
+import "log"
+
 func main() {
-	server := InitWebServer()
-	server.Run(":8080")
+	app := InitApp()
+	if err := app.Run(); err != nil {
+		log.Fatalln(err)
+	}
 }
@@ -1,32 +1,25 @@
 package ratelimit
 
 import (
-	_ "embed"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 	"log"
 	"net/http"
-	"time"
+	"webook/pkg/limiter"
 )
 
+// Builder 按照客户端 IP 限流
 type Builder struct {
-	prefix   string
-	cmd      redis.Cmdable
-	interval time.Duration
-	// 阈值
-	rate int
+	prefix  string
+	limiter limiter.Limiter
+	// 不限流的路径，例如健康检查
+	ignorePaths []string
 }
 
-//go:embed slide_window.lua
-var luaScript string
-
-func NewBuilder(cmd redis.Cmdable, interval time.Duration, rate int) *Builder {
+func NewBuilder(l limiter.Limiter) *Builder {
 	return &Builder{
-		cmd:      cmd,
-		prefix:   "ip-limiter",
-		interval: interval,
-		rate:     rate,
+		limiter: l,
+		prefix:  "ip-limiter",
 	}
 }
 
@@ -35,9 +28,22 @@ func (b *Builder) Prefix(prefix string) *Builder {
 	return b
 }
 
+func (b *Builder) IgnorePaths(path string) *Builder {
+	b.ignorePaths = append(b.ignorePaths, path)
+	return b
+}
+
 func (b *Builder) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		limited, err := b.limit(ctx)
+		for _, path := range b.ignorePaths {
+			if ctx.Request.URL.Path == path {
+				ctx.Next()
+				return
+			}
+		}
+
+		key := fmt.Sprintf("%s:%s", b.prefix, ctx.ClientIP())
+		limited, err := b.limiter.Limit(ctx, key)
 		if err != nil {
 			log.Println(err)
 			// 这一步很有意思，就是如果这边出错了
@@ -53,9 +59,3 @@ func (b *Builder) Build() gin.HandlerFunc {
 		ctx.Next()
 	}
 }
-
-func (b *Builder) limit(ctx *gin.Context) (bool, error) {
-	key := fmt.Sprintf("%s:%s", b.prefix, ctx.ClientIP())
-	return b.cmd.Eval(ctx, luaScript, []string{key},
-		b.interval.Milliseconds(), b.rate, time.Now().UnixMilli()).Bool()
-}
@@ -0,0 +1,47 @@
+package limiter
+
+import (
+	"context"
+	_ "embed"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed slide_window.lua
+var luaSlideWindow string
+
+// RedisSlidingWindowLimiter 基于 Redis 的滑动窗口限流，多个实例共享同一个窗口
+type RedisSlidingWindowLimiter struct {
+	cmd redis.Cmdable
+	// 限流参数可以在运行时调整
+	mu       sync.RWMutex
+	interval time.Duration
+	// 阈值，interval 内最多允许 rate 个请求
+	rate int
+}
+
+func NewRedisSlidingWindowLimiter(cmd redis.Cmdable, interval time.Duration, rate int) *RedisSlidingWindowLimiter {
+	return &RedisSlidingWindowLimiter{
+		cmd:      cmd,
+		interval: interval,
+		rate:     rate,
+	}
+}
+
+// 运行时调整限流参数，不需要重启服务
+func (l *RedisSlidingWindowLimiter) SetLimit(interval time.Duration, rate int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = interval
+	l.rate = rate
+}
+
+func (l *RedisSlidingWindowLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	l.mu.RLock()
+	interval, rate := l.interval, l.rate
+	l.mu.RUnlock()
+	return l.cmd.Eval(ctx, luaSlideWindow, []string{key},
+		interval.Milliseconds(), rate, time.Now().UnixMilli()).Bool()
+}
@@ -0,0 +1,8 @@
+package limiter
+
+import "context"
+
+type Limiter interface {
+	// Limit 是否触发限流，key 是限流对象，返回 true 表示触发了限流
+	Limit(ctx context.Context, key string) (bool, error)
+}
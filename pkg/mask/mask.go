@@ -0,0 +1,27 @@
+package mask
+
+import "strings"
+
+// Phone 手机号脱敏：138****1234
+func Phone(phone string) string {
+	if len(phone) < 7 {
+		return phone
+	}
+
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
+
+// Email 邮箱脱敏：保留用户名首尾字符，如 a***b@qq.com
+func Email(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+
+	name, domain := email[:at], email[at:]
+	if len(name) <= 2 {
+		return name[:1] + "***" + domain
+	}
+
+	return name[:1] + "***" + name[len(name)-1:] + domain
+}
@@ -0,0 +1,64 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 附录 B 中 SHA1 的测试向量
+func TestHotp_RFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	testCases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, hotp(key, uint64(tc.unix/period), 8))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	// 8 位验证码 07081804 的低 6 位
+	step, ok := Validate(secret, "081804", now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// 容忍前后一个时间步长
+	_, ok = Validate(secret, "081804", now.Add(period*time.Second))
+	assert.True(t, ok)
+	_, ok = Validate(secret, "081804", now.Add(2*period*time.Second))
+	assert.False(t, ok)
+
+	_, ok = Validate(secret, "000000", now)
+	assert.False(t, ok)
+	_, ok = Validate(secret, "81804", now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	code, err := Code(secret, Step(time.Now()))
+	require.NoError(t, err)
+	assert.Len(t, code, digits)
+
+	uri := URI("webook", "abc@qq.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/webook:abc@qq.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+}
@@ -10,37 +10,62 @@ import (
 	"webook/internal/web"
 	"webook/ioc"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 )
 
-func InitWebServer() *gin.Engine {
+func InitApp() *App {
 	wire.Build(
-		// 初始化第三方依赖
+		// 初始化配置与第三方依赖
+		ioc.InitConfigManager, ioc.InitConfig,
 		ioc.InitDB, ioc.InitRedis,
 
 		// 初始化DAO
 		dao.NewUserDAO,
+		dao.NewAsyncSmsDAO,
+		dao.NewSmsLogDAO,
+		dao.NewTotpDAO,
 
 		// 初始化缓存
 		cache.NewUserCache,
-		cache.NewCodeCache,
+		ioc.InitCodeCache,
+		cache.NewLoginLockCache,
 
 		// 初始化Repository
 		repository.NewUserRepository,
 		repository.NewCodeRepository,
+		repository.NewLoginLockRepository,
+		repository.NewAsyncSmsRepository,
+		ioc.InitSmsLogRepository,
+		repository.NewTotpRepository,
 
 		// 初始化Service
 		service.NewUserService,
+		ioc.InitCodePolicies,
 		service.NewCodeService,
+		service.NewEmailCodeService,
+		ioc.InitLoginLockPolicies,
+		service.NewLoginGuardService,
+		service.NewTotpService,
+		service.NewSmsLogService,
 
 		// 初始化Handler
+		ioc.InitSMSTemplates,
+		ioc.InitAsyncSMSService,
 		ioc.InitSMSService,
+		ioc.InitEmailService,
+		ioc.InitJWTHandler,
 		web.NewUserHandler,
+		web.NewHealthHandler,
+		ioc.InitSmsLogHandler,
+		ioc.InitWechatService,
+		ioc.InitOAuth2WechatHandler,
 
 		ioc.InitWebServer,
 		ioc.InitMiddlewares,
+		ioc.InitHTTPServer,
+
+		wire.Struct(new(App), "*"),
 	)
 
-	return new(gin.Engine)
+	return new(App)
 }
@@ -7,7 +7,6 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"webook/internal/repository"
 	"webook/internal/repository/cache"
 	"webook/internal/repository/dao"
@@ -18,19 +17,51 @@ import (
 
 // Injectors from wire.go:
 
-func InitWebServer() *gin.Engine {
-	cmdable := ioc.InitRedis()
-	v := ioc.InitMiddlewares(cmdable)
-	db := ioc.InitDB()
+func InitApp() *App {
+	manager := ioc.InitConfigManager()
+	config := ioc.InitConfig(manager)
+	cmdable := ioc.InitRedis(config)
+	handler := ioc.InitJWTHandler(config, cmdable)
+	v := ioc.InitMiddlewares(manager, cmdable, handler)
+	db := ioc.InitDB(config)
 	userDAO := dao.NewUserDAO(db)
 	userCache := cache.NewUserCache(cmdable)
 	userRepository := repository.NewUserRepository(userDAO, userCache)
 	userService := service.NewUserService(userRepository)
-	codeCache := cache.NewCodeCache(cmdable)
+	codeCache := ioc.InitCodeCache(config, cmdable)
 	codeRepository := repository.NewCodeRepository(codeCache)
-	smsService := ioc.InitSMSService()
-	codeService := service.NewCodeService(codeRepository, smsService)
-	userHandler := web.NewUserHandler(userService, codeService)
-	engine := ioc.InitWebServer(v, userHandler)
-	return engine
+	asyncSmsDAO := dao.NewAsyncSmsDAO(db)
+	asyncSmsRepository := repository.NewAsyncSmsRepository(asyncSmsDAO)
+	templates := ioc.InitSMSTemplates(manager)
+	smsLogDAO := dao.NewSmsLogDAO(db)
+	smsLogRepository := ioc.InitSmsLogRepository(config, smsLogDAO)
+	asyncService := ioc.InitAsyncSMSService(manager, asyncSmsRepository, templates, smsLogRepository)
+	smsService := ioc.InitSMSService(manager, asyncService, cmdable, templates)
+	codePolicies := ioc.InitCodePolicies(config)
+	codeService := service.NewCodeService(codeRepository, smsService, codePolicies)
+	emailService := ioc.InitEmailService(config)
+	emailCodeService := service.NewEmailCodeService(codeRepository, emailService, codePolicies)
+	loginLockCache := cache.NewLoginLockCache(cmdable)
+	loginLockRepository := repository.NewLoginLockRepository(loginLockCache)
+	loginLockPolicies := ioc.InitLoginLockPolicies(config)
+	loginGuardService := service.NewLoginGuardService(loginLockRepository, loginLockPolicies)
+	totpDAO := dao.NewTotpDAO(db)
+	totpRepository := repository.NewTotpRepository(totpDAO)
+	totpService := service.NewTotpService(totpRepository, userRepository)
+	userHandler := web.NewUserHandler(userService, codeService, emailCodeService, loginGuardService, totpService, handler)
+	healthHandler := web.NewHealthHandler(db, cmdable)
+	smsLogService := service.NewSmsLogService(smsLogRepository, asyncSmsRepository)
+	smsLogHandler := ioc.InitSmsLogHandler(config, smsLogService)
+	wechatService := ioc.InitWechatService(config)
+	oAuth2WechatHandler := ioc.InitOAuth2WechatHandler(config, wechatService, userService, totpService, handler)
+	engine := ioc.InitWebServer(config, v, userHandler, healthHandler, smsLogHandler, oAuth2WechatHandler)
+	server := ioc.InitHTTPServer(config, engine)
+	app := &App{
+		cfg:      config,
+		server:   server,
+		asyncSMS: asyncService,
+		db:       db,
+		redis:    cmdable,
+	}
+	return app
 }